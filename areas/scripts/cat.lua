@@ -0,0 +1,13 @@
+-- 고양이는 빵을 받으면 좋아합니다.
+function on_give(self, actor, item)
+  if item.name == "빵" then
+    execute(self, "야옹 말")
+    send(actor, format("{cat:이/가} 당신의 다리에 몸을 비빕니다.", {cat = self}))
+  end
+end
+
+function on_death(self, killer)
+  if killer then
+    send(killer, "어디선가 다른 고양이들이 당신을 노려봅니다.")
+  end
+end
@@ -0,0 +1,7 @@
+-- 광장의 분수에 소원을 빌면 조금 기운이 납니다.
+function on_say(self, actor, msg)
+  if string.find(msg, "소원") then
+    send(actor, "{c}분수의 물이 반짝이며 기운이 솟습니다.{x}")
+    set_stat(actor, "hp", stat(actor, "hp") + 5)
+  end
+end
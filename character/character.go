@@ -0,0 +1,145 @@
+package character
+
+import (
+	"github.com/zrma/mud/chat"
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/quest"
+)
+
+const (
+	baseAttribute = 10
+	maxLevel      = 50
+)
+
+type Attributes struct {
+	Strength     int `json:"strength"`
+	Dexterity    int `json:"dexterity"`
+	Constitution int `json:"constitution"`
+	Intelligence int `json:"intelligence"`
+	Wisdom       int `json:"wisdom"`
+}
+
+// Character is the persistent game state of a player apart from what it carries.
+type Character struct {
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+	Exp   int    `json:"exp"`
+
+	Attributes `json:"attributes"`
+
+	HP         int `json:"hp"`
+	MaxHP      int `json:"max_hp"`
+	MP         int `json:"mp"`
+	MaxMP      int `json:"max_mp"`
+	Stamina    int `json:"stamina"`
+	MaxStamina int `json:"max_stamina"`
+
+	Gold int `json:"gold"`
+
+	Quests *quest.Log     `json:"quests,omitempty"`
+	Chat   *chat.Settings `json:"chat,omitempty"`
+}
+
+func New(name string) *Character {
+	c := &Character{
+		Name:  name,
+		Level: 1,
+		Attributes: Attributes{
+			Strength:     baseAttribute,
+			Dexterity:    baseAttribute,
+			Constitution: baseAttribute,
+			Intelligence: baseAttribute,
+			Wisdom:       baseAttribute,
+		},
+	}
+	c.Recalculate()
+	c.HP, c.MP, c.Stamina = c.MaxHP, c.MaxMP, c.MaxStamina
+	return c
+}
+
+// Recalculate derives the maximum pools from level and attributes. Current values are capped.
+func (c *Character) Recalculate() {
+	c.MaxHP = 10 + c.Level*(5+c.Constitution/2)
+	c.MaxMP = 5 + c.Level*(2+c.Intelligence/3)
+	c.MaxStamina = 10 + c.Level*(3+(c.Constitution+c.Dexterity)/4)
+
+	c.HP = min(c.HP, c.MaxHP)
+	c.MP = min(c.MP, c.MaxMP)
+	c.Stamina = min(c.Stamina, c.MaxStamina)
+}
+
+// ExpToLevel is the total experience needed to reach the level after the given one.
+func ExpToLevel(level int) int {
+	return 100 * level * level
+}
+
+// GainExp adds experience and returns how many levels were gained. Every level raises all
+// attributes by one and refills the pools.
+func (c *Character) GainExp(exp int) int {
+	c.Exp += exp
+	gained := 0
+	for c.Level < maxLevel && c.Exp >= ExpToLevel(c.Level) {
+		c.Level++
+		gained++
+		c.Strength++
+		c.Dexterity++
+		c.Constitution++
+		c.Intelligence++
+		c.Wisdom++
+	}
+	if gained > 0 {
+		c.Recalculate()
+		c.HP, c.MP, c.Stamina = c.MaxHP, c.MaxMP, c.MaxStamina
+	}
+	return gained
+}
+
+// Regenerate restores a part of every pool and tells if anything changed.
+func (c *Character) Regenerate() bool {
+	hp := regen(c.HP, c.MaxHP, 1+c.Constitution/5)
+	mp := regen(c.MP, c.MaxMP, 1+c.Wisdom/5)
+	stamina := regen(c.Stamina, c.MaxStamina, 2+c.Constitution/5)
+
+	changed := hp != c.HP || mp != c.MP || stamina != c.Stamina
+	c.HP, c.MP, c.Stamina = hp, mp, stamina
+	return changed
+}
+
+func (c *Character) Dead() bool {
+	return c.HP <= 0
+}
+
+func regen(current, max, amount int) int {
+	if current >= max {
+		return current
+	}
+	return min(current+amount, max)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (c *Character) Stats() event.Stats {
+	return event.Stats{
+		Name:         c.Name,
+		Level:        c.Level,
+		Exp:          c.Exp,
+		ExpNext:      ExpToLevel(c.Level),
+		HP:           c.HP,
+		MaxHP:        c.MaxHP,
+		MP:           c.MP,
+		MaxMP:        c.MaxMP,
+		Stamina:      c.Stamina,
+		MaxStamina:   c.MaxStamina,
+		Gold:         c.Gold,
+		Strength:     c.Strength,
+		Dexterity:    c.Dexterity,
+		Constitution: c.Constitution,
+		Intelligence: c.Intelligence,
+		Wisdom:       c.Wisdom,
+	}
+}
@@ -0,0 +1,185 @@
+package chat
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Public channels every player is on until they leave them.
+const (
+	Gossip = "잡담"
+	Trade  = "거래"
+)
+
+var Public = []string{Gossip, Trade}
+
+var (
+	ErrNotJoined = errors.New("not on the channel")
+	ErrNoGuild   = errors.New("not in a guild")
+	ErrNotMember = errors.New("not a member of the guild")
+	ErrName      = errors.New("invalid channel name")
+)
+
+const maxNameLength = 12
+
+// Settings are the channels a player is on and the players they don't want to hear. They are
+// saved with the character.
+type Settings struct {
+	// Left are the public channels the player left.
+	Left  []string `json:"left,omitempty"`
+	Muted []string `json:"muted,omitempty"`
+	// Guild is the name of the guild channel the player is on. Only the channel of the guild the
+	// player is a member of can be joined.
+	Guild string `json:"guild,omitempty"`
+	// Member is the guild the player belongs to, which only an officer of the guild or a
+	// moderator sets. Officers may add players to their guild and remove them.
+	Member  string   `json:"member,omitempty"`
+	Officer bool     `json:"officer,omitempty"`
+	Ignore  []string `json:"ignore,omitempty"`
+}
+
+func NewSettings() *Settings {
+	return &Settings{}
+}
+
+func IsPublic(channel string) bool {
+	return contains(Public, channel)
+}
+
+// ValidName tells whether name can be the name of a guild channel.
+func ValidName(name string) bool {
+	return name != "" && !IsPublic(name) && len([]rune(name)) <= maxNameLength &&
+		!strings.ContainsAny(name, " {}")
+}
+
+// Channels are the channels the player is on, public ones first.
+func (s *Settings) Channels() []string {
+	var channels []string
+	for _, c := range Public {
+		if !contains(s.Left, c) {
+			channels = append(channels, c)
+		}
+	}
+	if s.Guild != "" && s.Guild == s.Member {
+		channels = append(channels, s.Guild)
+	}
+	return channels
+}
+
+func (s *Settings) Joined(channel string) bool {
+	if IsPublic(channel) {
+		return !contains(s.Left, channel)
+	}
+	return channel != "" && channel == s.Guild && channel == s.Member
+}
+
+// Join puts the player on a public channel, or on the channel of their guild.
+func (s *Settings) Join(channel string) error {
+	if IsPublic(channel) {
+		s.Left = remove(s.Left, channel)
+		return nil
+	}
+	if !ValidName(channel) {
+		return ErrName
+	}
+	if channel != s.Member {
+		return ErrNotMember
+	}
+	s.Guild = channel
+	return nil
+}
+
+// SetGuild makes the player a member of a guild and puts them on its channel, instead of the
+// guild they were in. An empty guild takes them out of their guild.
+func (s *Settings) SetGuild(guild string, officer bool) error {
+	if guild != "" && !ValidName(guild) {
+		return ErrName
+	}
+	if s.Member != guild {
+		s.Muted = remove(s.Muted, s.Member)
+	}
+	s.Member, s.Guild, s.Officer = guild, guild, officer && guild != ""
+	return nil
+}
+
+func (s *Settings) Leave(channel string) error {
+	if !s.Joined(channel) {
+		return ErrNotJoined
+	}
+	if IsPublic(channel) {
+		s.Left = add(s.Left, channel)
+	} else {
+		s.Guild = ""
+	}
+	s.Muted = remove(s.Muted, channel)
+	return nil
+}
+
+// Mute stops or restarts the delivery of a channel the player stays on and tells whether it is
+// muted now.
+func (s *Settings) Mute(channel string) (bool, error) {
+	if !s.Joined(channel) {
+		return false, ErrNotJoined
+	}
+	if contains(s.Muted, channel) {
+		s.Muted = remove(s.Muted, channel)
+		return false, nil
+	}
+	s.Muted = add(s.Muted, channel)
+	return true, nil
+}
+
+func (s *Settings) IsMuted(channel string) bool {
+	return contains(s.Muted, channel)
+}
+
+// ToggleIgnore starts or stops ignoring a player and tells whether they are ignored now.
+func (s *Settings) ToggleIgnore(name string) bool {
+	if contains(s.Ignore, name) {
+		s.Ignore = remove(s.Ignore, name)
+		return false
+	}
+	s.Ignore = add(s.Ignore, name)
+	return true
+}
+
+func (s *Settings) Ignores(name string) bool {
+	return contains(s.Ignore, name)
+}
+
+// Hears tells whether a message of sender on channel reaches the player.
+func (s *Settings) Hears(channel, sender string) bool {
+	return s.Joined(channel) && !s.IsMuted(channel) && !s.Ignores(sender)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func add(list []string, s string) []string {
+	if contains(list, s) {
+		return list
+	}
+	list = append(list, s)
+	sort.Strings(list)
+	return list
+}
+
+func remove(list []string, s string) []string {
+	result := list[:0]
+	for _, v := range list {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
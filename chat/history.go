@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zrma/mud/markup"
+)
+
+// Entry is one message kept in a history. Text is the message as it was said, without the
+// sender or any markup.
+type Entry struct {
+	Time    time.Time
+	Channel string
+	Sender  string
+	Text    string
+}
+
+// Markup is the entry as a line of color markup, with the time it was said.
+func (e Entry) Markup() string {
+	line := "{K}[" + e.Time.Format("15:04") + "]{x} "
+	if e.Channel != "" {
+		line += "{Y}[" + markup.Escape(e.Channel) + "]{x} "
+	}
+	return line + "{C}" + markup.Escape(e.Sender) + "{x}: " + markup.Escape(e.Text)
+}
+
+// History keeps the last messages of a channel or room, dropping the oldest ones once it is
+// full.
+type History struct {
+	size    int
+	entries []Entry
+	next    int
+}
+
+func NewHistory(size int) *History {
+	return &History{size: size}
+}
+
+func (h *History) Add(e Entry) {
+	if h.size <= 0 {
+		return
+	}
+	if len(h.entries) < h.size {
+		h.entries = append(h.entries, e)
+		return
+	}
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % h.size
+}
+
+// Last returns up to n of the newest entries, oldest first.
+func (h *History) Last(n int) []Entry {
+	ordered := append(append([]Entry(nil), h.entries[h.next:]...), h.entries[:h.next]...)
+	if n >= 0 && n < len(ordered) {
+		ordered = ordered[len(ordered)-n:]
+	}
+	return ordered
+}
+
+// Merge combines entries of several histories in the order they were said and keeps the newest
+// n of them.
+func Merge(n int, lists ...[]Entry) []Entry {
+	var all []Entry
+	for _, l := range lists {
+		all = append(all, l...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Time.Before(all[j].Time)
+	})
+	if n >= 0 && n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
@@ -0,0 +1,171 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A script is a list of commands, one per line. Lines starting with # are comments, and lines
+// starting with @ are directives for the runner:
+//
+//	@sleep 500ms
+//	@wait <regexp> [timeout]
+//	@expect <regexp> [timeout]
+//
+// @wait gives up silently after the timeout while @expect fails the script.
+const (
+	comment   = "#"
+	directive = "@"
+
+	defaultTimeout = 10 * time.Second
+)
+
+type StepKind int
+
+const (
+	Command StepKind = 0 + iota
+	Sleep
+	Wait
+	Expect
+)
+
+type Step struct {
+	Kind     StepKind
+	Line     int
+	Command  string
+	Pattern  *regexp.Regexp
+	Duration time.Duration
+}
+
+var ErrTimeout = errors.New("timed out waiting for output")
+
+func Parse(r io.Reader) ([]Step, error) {
+	var steps []Step
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, comment) {
+			continue
+		}
+		if !strings.HasPrefix(line, directive) {
+			steps = append(steps, Step{Kind: Command, Line: n, Command: line})
+			continue
+		}
+
+		step, err := parseDirective(strings.Fields(line[len(directive):]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		step.Line = n
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+func parseDirective(fields []string) (Step, error) {
+	if len(fields) == 0 {
+		return Step{}, errors.New("empty directive")
+	}
+
+	switch fields[0] {
+	case "sleep":
+		if len(fields) != 2 {
+			return Step{}, errors.New("usage: @sleep <duration>")
+		}
+		d, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return Step{}, err
+		}
+		return Step{Kind: Sleep, Duration: d}, nil
+	case "wait", "expect":
+		if len(fields) < 2 || len(fields) > 3 {
+			return Step{}, fmt.Errorf("usage: @%s <regexp> [timeout]", fields[0])
+		}
+		re, err := regexp.Compile(fields[1])
+		if err != nil {
+			return Step{}, err
+		}
+		step := Step{Kind: Wait, Pattern: re, Duration: defaultTimeout}
+		if fields[0] == "expect" {
+			step.Kind = Expect
+		}
+		if len(fields) == 3 {
+			if step.Duration, err = time.ParseDuration(fields[2]); err != nil {
+				return Step{}, err
+			}
+		}
+		return step, nil
+	}
+	return Step{}, errors.New(fmt.Sprintln("unknown directive", fields[0]))
+}
+
+// Run executes the steps in order, pausing delay between commands. Waits consume the lines on
+// output until one of them matches, so output that arrived before the wait started counts too.
+func Run(ctx context.Context, steps []Step, delay time.Duration, output <-chan string,
+	execute func(string) error) error {
+	for i, step := range steps {
+		switch step.Kind {
+		case Command:
+			if i > 0 && delay > 0 {
+				if err := sleep(ctx, delay); err != nil {
+					return err
+				}
+			}
+			if err := execute(step.Command); err != nil {
+				return fmt.Errorf("line %d: %v", step.Line, err)
+			}
+		case Sleep:
+			if err := sleep(ctx, step.Duration); err != nil {
+				return err
+			}
+		case Wait, Expect:
+			err := wait(ctx, step.Pattern, step.Duration, output)
+			if err == ErrTimeout && step.Kind == Wait {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("line %d: %v: %s", step.Line, err, step.Pattern)
+			}
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func wait(ctx context.Context, pattern *regexp.Regexp, timeout time.Duration, output <-chan string) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line := <-output:
+			if pattern.MatchString(line) {
+				return nil
+			}
+		case <-timer.C:
+			return ErrTimeout
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 
+	"github.com/zrma/mud/client/record"
 	"github.com/zrma/mud/logging"
+	"github.com/zrma/mud/markup"
 	"github.com/zrma/mud/pb"
 )
 
@@ -27,6 +29,38 @@ type Client struct {
 
 	conn *grpc.ClientConn
 	pb.MudClient
+
+	recorder *record.Recorder
+	password string
+}
+
+// SetPassword signs in with a password. The first password an account is given becomes its own.
+func (c *Client) SetPassword(password string) {
+	c.password = password
+}
+
+// SetRecorder logs every message sent to and received from the server from now on.
+func (c *Client) SetRecorder(r *record.Recorder) {
+	c.recorder = r
+}
+
+func (c *Client) record(direction record.Direction, msg string) {
+	if c.recorder == nil {
+		return
+	}
+	if err := c.recorder.Record(direction, msg); err != nil {
+		c.logger.Warn(
+			"recording failed",
+			"direction", direction,
+			"err", err,
+		)
+	}
+}
+
+// recordError logs the error a request failed with as received, right after the line that was
+// sent, so that a recording shows what the server refused.
+func (c *Client) recordError(err error) {
+	c.record(record.Received, "{R}"+markup.Escape(err.Error())+"{x}")
 }
 
 func (c *Client) Init() error {
@@ -56,7 +90,8 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-func (c *Client) PingPong() (string, error) {
+// PingPong signs in, or renews the given token while keeping its session.
+func (c *Client) PingPong(token string) (string, error) {
 	host, err := os.Hostname()
 	if err != nil {
 		return "", err
@@ -64,7 +99,7 @@ func (c *Client) PingPong() (string, error) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	r, err := c.Ping(ctx, &pb.PingRequest{Name: host})
+	r, err := c.Ping(ctx, &pb.PingRequest{Name: host, Token: token, Password: c.password})
 	if err != nil {
 		return "", err
 	}
@@ -78,21 +113,58 @@ func (c *Client) PingPong() (string, error) {
 	return r.GetToken(), nil
 }
 
-func (c *Client) SendMessage(token, msg string) error {
+// SendMessage talks to a player when target is set and on a channel otherwise.
+func (c *Client) SendMessage(token, channel, target, msg string) error {
+	c.record(record.Sent, msg)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 	_, err := c.Message(ctx, &pb.MessageRequest{
-		Token: token,
-		Msg:   msg,
+		Token:   token,
+		Msg:     msg,
+		Channel: channel,
+		Target:  target,
 	})
 	if err != nil {
+		c.recordError(err)
 		return err
 	}
+	return nil
+}
+
+func (c *Client) SendCommand(token, line string) error {
+	c.record(record.Sent, line)
 
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := c.Command(ctx, &pb.CommandRequest{
+		Token: token,
+		Line:  line,
+	})
+	if err != nil {
+		c.recordError(err)
+		return err
+	}
 	return nil
 }
 
-func (c *Client) Subscribe(ctx context.Context, token string, f func(string) error) error {
+// FetchHistory returns recent messages of a channel, or of the room and every channel of the
+// player when channel is empty.
+func (c *Client) FetchHistory(token, channel string, limit int) ([]*pb.HistoryEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	r, err := c.History(ctx, &pb.HistoryRequest{
+		Token:   token,
+		Channel: channel,
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetEntries(), nil
+}
+
+func (c *Client) Subscribe(ctx context.Context, token string, f func(*pb.ReceiveReply) error) error {
 	stream, err := c.Receive(ctx, &pb.ReceiveRequest{
 		Token: token,
 	})
@@ -108,7 +180,10 @@ func (c *Client) Subscribe(ctx context.Context, token string, f func(string) err
 		if err != nil {
 			return err
 		}
-		if err := f(r.GetMsg()); err != nil {
+		if r.GetMsg() != "" {
+			c.record(record.Received, r.GetMsg())
+		}
+		if err := f(r); err != nil {
 			return err
 		}
 	}
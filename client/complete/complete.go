@@ -0,0 +1,60 @@
+package complete
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Completer completes the word under the cursor from named sources of candidates, such as the
+// command registry or the exits and names the server pushed for the current room. It satisfies
+// readline.AutoCompleter.
+type Completer struct {
+	sync.RWMutex
+
+	sources map[string][]string
+}
+
+func New() *Completer {
+	return &Completer{sources: make(map[string][]string)}
+}
+
+// Set replaces the candidates of a source.
+func (c *Completer) Set(source string, words []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.sources[source] = append([]string(nil), words...)
+}
+
+func (c *Completer) Do(line []rune, pos int) ([][]rune, int) {
+	start := pos
+	for start > 0 && !unicode.IsSpace(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+
+	c.RLock()
+	defer c.RUnlock()
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, words := range c.sources {
+		for _, w := range words {
+			if seen[w] || !strings.HasPrefix(w, prefix) || w == prefix {
+				continue
+			}
+			seen[w] = true
+			candidates = append(candidates, w)
+		}
+	}
+	sort.Strings(candidates)
+
+	length := len([]rune(prefix))
+	result := make([][]rune, 0, len(candidates))
+	for _, w := range candidates {
+		result = append(result, []rune(w)[length:])
+	}
+	return result, length
+}
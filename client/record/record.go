@@ -0,0 +1,191 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Direction string
+
+const (
+	Sent     Direction = "sent"
+	Received Direction = "received"
+)
+
+type Format int
+
+const (
+	Text Format = 0 + iota
+	JSONL
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "text", "":
+		return Text, nil
+	case "jsonl", "json":
+		return JSONL, nil
+	}
+	return Text, errors.New(fmt.Sprintln("unknown record format", s))
+}
+
+const timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+var markers = map[Direction]string{
+	Sent:     ">",
+	Received: "<",
+}
+
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Direction Direction `json:"direction"`
+	Msg       string    `json:"msg"`
+}
+
+func Create(path string, format Format) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: f, w: bufio.NewWriter(f), format: format}, nil
+}
+
+// Recorder appends every entry to a log file, either as human readable lines or as one JSON
+// object per line.
+type Recorder struct {
+	sync.Mutex
+
+	file   *os.File
+	w      *bufio.Writer
+	format Format
+}
+
+func (r *Recorder) Record(direction Direction, msg string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	entry := Entry{Time: time.Now(), Direction: direction, Msg: msg}
+	switch r.format {
+	case JSONL:
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := r.w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	case Text:
+		for _, line := range strings.Split(msg, "\n") {
+			if _, err := fmt.Fprintf(r.w, "%s %s %s\n",
+				entry.Time.Format(timeLayout), markers[direction], line); err != nil {
+				return err
+			}
+		}
+	}
+	return r.w.Flush()
+}
+
+func (r *Recorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := r.w.Flush(); err != nil {
+		return err
+	}
+	return r.file.Close()
+}
+
+// Load reads a recording in either format. The format is detected line by line.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return read(f)
+}
+
+func read(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func parseLine(line string) (Entry, error) {
+	var entry Entry
+	if strings.HasPrefix(line, "{") {
+		err := json.Unmarshal([]byte(line), &entry)
+		return entry, err
+	}
+
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 2 {
+		return entry, errors.New("malformed entry")
+	}
+	t, err := time.Parse(timeLayout, fields[0])
+	if err != nil {
+		return entry, err
+	}
+	entry.Time = t
+
+	switch fields[1] {
+	case markers[Sent]:
+		entry.Direction = Sent
+	case markers[Received]:
+		entry.Direction = Received
+	default:
+		return entry, errors.New(fmt.Sprintln("unknown direction", fields[1]))
+	}
+	if len(fields) == 3 {
+		entry.Msg = fields[2]
+	}
+	return entry, nil
+}
+
+// Replay calls f for every entry, waiting the recorded gap between entries divided by speed.
+// A speed of zero or less plays everything back without waiting.
+func Replay(ctx context.Context, entries []Entry, speed float64, f func(Entry) error) error {
+	for i, entry := range entries {
+		if i > 0 && speed > 0 {
+			gap := time.Duration(float64(entry.Time.Sub(entries[i-1].Time)) / speed)
+			if gap > 0 {
+				timer := time.NewTimer(gap)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+		}
+		if err := f(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
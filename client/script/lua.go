@@ -0,0 +1,122 @@
+package script
+
+import (
+	"regexp"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// newState opens only the libraries that can't reach outside of the client. os, io, package and
+// debug are left out, and so are the base functions that read files.
+func newState(e *Engine) *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	for _, lib := range []struct {
+		name string
+		f    lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.f))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	api := map[string]lua.LGFunction{
+		// send("안녕 말") executes a command as if it was typed.
+		"send": func(L *lua.LState) int {
+			e.send(L.CheckString(1))
+			return 0
+		},
+		// echo("text") prints to the local screen only.
+		"echo": func(L *lua.LState) int {
+			e.echo(L.CheckString(1))
+			return 0
+		},
+		// alias("ㅎ", "안녕 말") or alias("ㅎ", function(args) return "안녕 말" end)
+		"alias": func(L *lua.LState) int {
+			a := &alias{}
+			switch v := L.CheckAny(2).(type) {
+			case *lua.LFunction:
+				a.fn = v
+			default:
+				a.expansion = L.CheckString(2)
+			}
+			e.aliases[L.CheckString(1)] = a
+			return 0
+		},
+		// trigger("^(.+)님이 들어왔습니다", "$1 안녕 말") or trigger(pattern, function(matches) end)
+		"trigger": func(L *lua.LState) int {
+			t := &trigger{pattern: checkRegexp(L, 1)}
+			switch v := L.CheckAny(2).(type) {
+			case *lua.LFunction:
+				t.fn = v
+			default:
+				t.command = L.CheckString(2)
+			}
+			e.triggers = append(e.triggers, t)
+			return 0
+		},
+		// highlight("귓속말", "{Y}") colors every match of the pattern.
+		"highlight": func(L *lua.LState) int {
+			e.triggers = append(e.triggers, &trigger{
+				pattern:   checkRegexp(L, 1),
+				highlight: L.CheckString(2),
+			})
+			return 0
+		},
+		// after(seconds, function() end) runs once, every(seconds, function() end) repeats.
+		"after": func(L *lua.LState) int {
+			e.addTimer(checkDuration(L, 1), L.CheckFunction(2), false)
+			return 0
+		},
+		"every": func(L *lua.LState) int {
+			e.addTimer(checkDuration(L, 1), L.CheckFunction(2), true)
+			return 0
+		},
+		// lines(n) returns the last n received lines, oldest first. A negative n returns none.
+		"lines": func(L *lua.LState) int {
+			n := L.OptInt(1, len(e.lines))
+			if n > len(e.lines) {
+				n = len(e.lines)
+			}
+			if n < 0 {
+				n = 0
+			}
+			tbl := L.NewTable()
+			for _, line := range e.lines[len(e.lines)-n:] {
+				tbl.Append(lua.LString(line))
+			}
+			L.Push(tbl)
+			return 1
+		},
+	}
+	for name, f := range api {
+		L.SetGlobal(name, L.NewFunction(f))
+	}
+
+	return L
+}
+
+func checkRegexp(L *lua.LState, n int) *regexp.Regexp {
+	re, err := regexp.Compile(L.CheckString(n))
+	if err != nil {
+		L.ArgError(n, err.Error())
+	}
+	return re
+}
+
+func checkDuration(L *lua.LState, n int) time.Duration {
+	d := time.Duration(float64(L.CheckNumber(n)) * float64(time.Second))
+	if d <= 0 {
+		L.ArgError(n, "duration must be positive")
+	}
+	return d
+}
@@ -0,0 +1,272 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/zrma/mud/markup"
+)
+
+const (
+	maxExpandDepth = 10
+	maxLines       = 100
+	queueSize      = 256
+	// callTimeout bounds every call into Lua, so that a script stuck in a loop can't keep the
+	// engine locked for good.
+	callTimeout = time.Second
+)
+
+var ErrTimeout = errors.New("script ran out of time")
+
+// Engine holds the user defined aliases, triggers and timers of a client and the Lua state that
+// backs them. Every call into Lua is serialized by the engine lock.
+type Engine struct {
+	sync.Mutex
+
+	state    *lua.LState
+	aliases  map[string]*alias
+	triggers []*trigger
+	timers   []*time.Timer
+	lines    []string
+	closed   bool
+
+	queue chan string
+	echo  func(string)
+}
+
+type alias struct {
+	expansion string
+	fn        *lua.LFunction
+}
+
+type trigger struct {
+	pattern   *regexp.Regexp
+	command   string
+	fn        *lua.LFunction
+	highlight string
+}
+
+func New(echo func(string)) *Engine {
+	e := &Engine{
+		aliases: make(map[string]*alias),
+		queue:   make(chan string, queueSize),
+		echo:    echo,
+	}
+	e.state = newState(e)
+	return e
+}
+
+// Commands delivers the lines sent by aliases, triggers and timers. They should be executed as
+// if the user typed them.
+func (e *Engine) Commands() <-chan string {
+	return e.queue
+}
+
+func (e *Engine) Close() {
+	e.Lock()
+	defer e.Unlock()
+
+	for _, t := range e.timers {
+		t.Stop()
+	}
+	e.timers = nil
+	e.closed = true
+	e.state.Close()
+}
+
+func (e *Engine) LoadFile(path string) error {
+	e.Lock()
+	defer e.Unlock()
+
+	return e.call(func() error {
+		return e.state.DoFile(path)
+	})
+}
+
+func (e *Engine) Run(code string) error {
+	e.Lock()
+	defer e.Unlock()
+
+	return e.call(func() error {
+		return e.state.DoString(code)
+	})
+}
+
+func (e *Engine) SetAlias(name, expansion string) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.aliases[name] = &alias{expansion: expansion}
+}
+
+func (e *Engine) RemoveAlias(name string) bool {
+	e.Lock()
+	defer e.Unlock()
+
+	if _, ok := e.aliases[name]; !ok {
+		return false
+	}
+	delete(e.aliases, name)
+	return true
+}
+
+// Expand replaces the command word of an input line if it is an alias. Like regular commands the
+// alias is the last word and the words in front of it are its arguments, available as $1, $2, ...
+// and $* in the expansion.
+func (e *Engine) Expand(input string) ([]string, error) {
+	e.Lock()
+	defer e.Unlock()
+
+	return e.expand(input, 0)
+}
+
+func (e *Engine) expand(input string, depth int) ([]string, error) {
+	if depth > maxExpandDepth {
+		return nil, errors.New(fmt.Sprintln("alias expansion too deep", input))
+	}
+
+	words := strings.Fields(input)
+	if len(words) == 0 {
+		return []string{input}, nil
+	}
+	args, word := words[:len(words)-1], words[len(words)-1]
+
+	a, ok := e.aliases[word]
+	if !ok {
+		return []string{input}, nil
+	}
+
+	var expanded []string
+	if a.fn != nil {
+		tbl := e.state.NewTable()
+		for _, arg := range args {
+			tbl.Append(lua.LString(arg))
+		}
+		if err := e.call(func() error {
+			return e.state.CallByParam(lua.P{Fn: a.fn, NRet: 1, Protect: true}, tbl)
+		}); err != nil {
+			return nil, err
+		}
+		ret := e.state.Get(-1)
+		e.state.Pop(1)
+		if ret == lua.LNil {
+			return nil, nil
+		}
+		expanded = strings.Split(ret.String(), ";")
+	} else {
+		expanded = strings.Split(substitute(a.expansion, args), ";")
+	}
+
+	var result []string
+	for _, line := range expanded {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines, err := e.expand(line, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, lines...)
+	}
+	return result, nil
+}
+
+func substitute(expansion string, args []string) string {
+	s := strings.Replace(expansion, "$*", strings.Join(args, " "), -1)
+	for i := len(args); i > 0; i-- {
+		s = strings.Replace(s, fmt.Sprintf("$%d", i), args[i-1], -1)
+	}
+	return s
+}
+
+// Process runs the triggers against a received line and returns the line to display, with
+// highlights applied as color markup. Patterns are matched against the text without markup.
+func (e *Engine) Process(line string) string {
+	e.Lock()
+	defer e.Unlock()
+
+	plain := markup.Strip(line)
+	e.lines = append(e.lines, plain)
+	if len(e.lines) > maxLines {
+		e.lines = e.lines[len(e.lines)-maxLines:]
+	}
+
+	for _, t := range e.triggers {
+		matches := t.pattern.FindStringSubmatch(plain)
+		if matches == nil {
+			continue
+		}
+
+		if t.highlight != "" {
+			line = t.pattern.ReplaceAllStringFunc(line, func(s string) string {
+				return t.highlight + s + "{x}"
+			})
+		}
+		if t.command != "" {
+			e.send(substitute(t.command, matches[1:]))
+		}
+		if t.fn != nil {
+			tbl := e.state.NewTable()
+			for _, m := range matches {
+				tbl.Append(lua.LString(m))
+			}
+			if err := e.call(func() error {
+				return e.state.CallByParam(lua.P{Fn: t.fn, NRet: 0, Protect: true}, tbl)
+			}); err != nil {
+				e.echo(fmt.Sprint("트리거 실행 중 에러가 발생했습니다.: ", err))
+			}
+		}
+	}
+	return line
+}
+
+// call runs f, which calls into Lua, and stops the script when it runs longer than callTimeout.
+func (e *Engine) call(f func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	e.state.SetContext(ctx)
+	err := f()
+	e.state.RemoveContext()
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return ErrTimeout
+	}
+	return err
+}
+
+func (e *Engine) send(line string) {
+	select {
+	case e.queue <- line:
+	default:
+		e.echo(fmt.Sprint("명령어 대기열이 가득 찼습니다.: ", line))
+	}
+}
+
+func (e *Engine) addTimer(d time.Duration, fn *lua.LFunction, repeat bool) {
+	var t *time.Timer
+	t = time.AfterFunc(d, func() {
+		e.Lock()
+		defer e.Unlock()
+
+		if e.closed {
+			return
+		}
+		if err := e.call(func() error {
+			return e.state.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true})
+		}); err != nil {
+			e.echo(fmt.Sprint("타이머 실행 중 에러가 발생했습니다.: ", err))
+		}
+		if repeat {
+			t.Reset(d)
+		}
+	})
+	e.timers = append(e.timers, t)
+}
@@ -1,22 +1,34 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/chzyer/readline"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/zrma/mud/chat"
 	"github.com/zrma/mud/client"
+	"github.com/zrma/mud/client/batch"
+	"github.com/zrma/mud/client/complete"
+	"github.com/zrma/mud/client/record"
+	"github.com/zrma/mud/client/script"
 	"github.com/zrma/mud/command"
+	"github.com/zrma/mud/event"
 	"github.com/zrma/mud/logging"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/pb"
 )
 
 const (
@@ -25,6 +37,10 @@ const (
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	const (
 		dev  = "development"
 		prod = "production"
@@ -43,6 +59,18 @@ func main() {
 		logLevel = logging.Dev
 	}
 
+	initScript := flag.String("init", defaultInitScript(), "lua script with aliases, triggers and timers")
+	recordPath := flag.String("record", "", "file to record sent and received messages to")
+	recordFormat := flag.String("record-format", "text", "recording format, text or jsonl")
+	batchPath := flag.String("batch", "", "run the commands of a script file, - for stdin, and exit")
+	delay := flag.Duration("delay", 0, "pause between commands in batch mode")
+	scrollback := flag.Int("scrollback", 20, "recent messages to show on connect, 0 for none")
+	flag.Parse()
+
+	if flag.Arg(0) == "replay" {
+		return replay(flag.Args()[1:])
+	}
+
 	logger, err := logging.NewLogger(logLevel)
 	if err != nil {
 		log.Fatalln(err)
@@ -53,12 +81,13 @@ func main() {
 	)
 
 	c := client.New(logger, host, port)
+	c.SetPassword(os.Getenv("MUD_PASSWORD"))
 	if err := c.Init(); err != nil {
 		logger.Err(
 			"client initializing failed",
 			"err", err,
 		)
-		return
+		return 1
 	}
 	defer func() {
 		if err := c.Close(); err != nil {
@@ -69,24 +98,139 @@ func main() {
 		}
 	}()
 
+	if *recordPath != "" {
+		format, err := record.ParseFormat(*recordFormat)
+		if err != nil {
+			logger.Err(
+				"invalid record format",
+				"err", err,
+			)
+			return 1
+		}
+		recorder, err := record.Create(*recordPath, format)
+		if err != nil {
+			logger.Err(
+				"recorder creating failed",
+				"path", *recordPath,
+				"err", err,
+			)
+			return 1
+		}
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				logger.Err(
+					"recorder closing failed",
+					"err", err,
+				)
+			}
+		}()
+		c.SetRecorder(recorder)
+	}
+
 	const (
-		lf         = '\n'
 		cr         = '\r'
-		lfStr      = string(lf)
 		crStr      = string(cr)
 		whitespace = " "
 	)
 
-	authToken, err := c.PingPong()
+	authToken, err := c.PingPong("")
+	if status.Code(err) == codes.Unauthenticated {
+		fmt.Println("비밀번호가 맞지 않습니다. MUD_PASSWORD를 확인해 보세요.")
+	}
 	if err != nil {
 		logger.Err(
 			"api request failed",
 			"method", "Ping",
 			"err", err,
 		)
-		return
+		return 1
+	}
+
+	colorMode := markup.Color16
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		colorMode = markup.None
+	}
+
+	var steps []batch.Step
+	if *batchPath != "" {
+		steps, err = loadBatch(*batchPath)
+		if err != nil {
+			logger.Err(
+				"batch script loading failed",
+				"path", *batchPath,
+				"err", err,
+			)
+			return 2
+		}
+	}
+
+	completer := complete.New()
+	completer.Set("commands", command.Words())
+
+	var rl *readline.Instance
+	var out io.Writer = os.Stdout
+	if *batchPath == "" {
+		rl, err = readline.NewEx(&readline.Config{
+			Prompt:       "> ",
+			HistoryFile:  historyFile(),
+			AutoComplete: completer,
+		})
+		if err != nil {
+			logger.Err(
+				"line editor initializing failed",
+				"err", err,
+			)
+			return 1
+		}
+		defer func() {
+			if err := rl.Close(); err != nil {
+				logger.Err(
+					"line editor closing failed",
+					"err", err,
+				)
+			}
+		}()
+		out = rl.Stdout()
+	}
+
+	engine := script.New(func(msg string) {
+		fmt.Fprintln(out, msg)
+	})
+	defer engine.Close()
+	if *initScript != "" {
+		if err := engine.LoadFile(*initScript); err != nil {
+			logger.Err(
+				"script loading failed",
+				"path", *initScript,
+				"err", err,
+			)
+		}
+	}
+
+	if *scrollback > 0 {
+		entries, err := c.FetchHistory(authToken, "", *scrollback)
+		if err != nil {
+			logger.Warn(
+				"api request failed",
+				"method", "History",
+				"err", err,
+			)
+		}
+		renderer := markup.NewRenderer(colorMode)
+		for _, e := range entries {
+			line := chat.Entry{
+				Time:    time.Unix(0, e.GetTime()*int64(time.Millisecond)),
+				Channel: e.GetChannel(),
+				Sender:  e.GetSender(),
+				Text:    e.GetMsg(),
+			}.Markup()
+			fmt.Fprintln(out, markup.Render(line, renderer))
+		}
 	}
 
+	var exitCode int32
+	// editing is 1 while the server editor is open, see onEvent
+	var editing int32
 	var mutex sync.RWMutex
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
@@ -107,13 +251,14 @@ func main() {
 		for ctx.Err() == nil {
 			select {
 			case <-ticker.C:
-				t, err := c.PingPong()
+				t, err := c.PingPong(token)
 				if err != nil {
 					logger.Err(
 						"api request failed",
 						"method", "Ping",
 						"err", err,
 					)
+					continue
 				}
 				if token != t {
 					token = t
@@ -129,6 +274,8 @@ func main() {
 		}
 	}()
 
+	output := make(chan string, 1024)
+
 	wg.Add(1)
 	go func() {
 		defer func() {
@@ -140,8 +287,26 @@ func main() {
 		token := authToken
 		mutex.RUnlock()
 
-		if err := c.Subscribe(ctx, token, func(msg string) error {
-			fmt.Println(msg)
+		if err := c.Subscribe(ctx, token, func(r *pb.ReceiveReply) error {
+			if r.GetKind() != "" {
+				onEvent(completer, rl, &editing, event.Kind(r.GetKind()), r.GetData(), logger)
+			}
+			if r.GetMsg() == "" {
+				return nil
+			}
+
+			mutex.RLock()
+			renderer := markup.NewRenderer(colorMode)
+			mutex.RUnlock()
+
+			msg := engine.Process(r.GetMsg())
+			fmt.Fprintln(out, markup.Render(msg, renderer))
+			if *batchPath != "" {
+				select {
+				case output <- markup.Strip(msg):
+				default:
+				}
+			}
 			return nil
 		}); err != nil && status.Code(err) != codes.Canceled {
 			logger.Err(
@@ -149,61 +314,174 @@ func main() {
 				"method", "Subscribe",
 				"err", err,
 			)
+			atomic.StoreInt32(&exitCode, 1)
 		}
 	}()
 
-	for ctx.Err() == nil {
-		reader := bufio.NewReader(os.Stdin)
+	type request struct {
+		input  string
+		result chan error
+	}
+	requests := make(chan request)
+	submit := func(input string) error {
+		r := request{input: input, result: make(chan error, 1)}
+		select {
+		case requests <- r:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return <-r.result
+	}
 
-		input, err := reader.ReadString(lf)
-		if err != nil {
-			if err == io.EOF {
-				logger.Info(
-					"input continue",
-					"err", err,
-				)
-				continue
+	if *batchPath != "" {
+		go func() {
+			defer cancel()
+
+			if err := batch.Run(ctx, steps, *delay, output, submit); err != nil && err != context.Canceled {
+				fmt.Fprintln(os.Stderr, "배치 스크립트가 실패했습니다.:", err)
+				atomic.StoreInt32(&exitCode, 1)
 			}
+		}()
+	} else {
+		go func() {
+			for ctx.Err() == nil {
+				input, err := rl.Readline()
+				if err != nil {
+					if err == io.EOF {
+						logger.Info(
+							"input closed",
+							"err", err,
+						)
+					} else if err != readline.ErrInterrupt {
+						logger.Err(
+							"input failed",
+							"err", err,
+						)
+					}
+					cancel()
+					return
+				}
+
+				_ = submit(strings.TrimRight(input, crStr))
+			}
+		}()
+	}
+
+	send := func(input string) error {
+		mutex.RLock()
+		err := c.SendCommand(authToken, input)
+		mutex.RUnlock()
+		if status.Code(err) == codes.NotFound {
+			fmt.Println("그런 명령어는 찾을 수 없습니다:", input)
+			return errors.New(fmt.Sprintln("unknown command", input))
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			fmt.Println("권한이 없습니다:", input)
+			return err
+		}
+		if status.Code(err) == codes.ResourceExhausted {
+			fmt.Println("너무 빠르게 입력하고 있습니다. 잠시 후에 다시 해 보세요.")
+			return err
+		}
+		if err != nil {
 			logger.Err(
-				"input failed",
+				"api request failed",
+				"method", "Command",
 				"err", err,
 			)
-			return
 		}
+		return err
+	}
 
-		input = strings.TrimRight(input, lfStr)
-		input = strings.TrimRight(input, crStr)
+	execute := func(input string) error {
 		inputs := strings.Split(input, whitespace)
 
-		_, token := inputs[:len(inputs)-1], inputs[len(inputs)-1]
+		args, token := inputs[:len(inputs)-1], inputs[len(inputs)-1]
 		cmd, ok := command.Find(token)
 		if !ok {
-			fmt.Println("그런 명령어는 찾을 수 없습니다:", input)
-			continue
+			return send(input)
 		}
 
 		v, err := cmd.Func()
 		if err != nil {
 			fmt.Println("명령어를 실행하는 도중 에러가 발생했습니다.:", err)
+			return err
 		}
 
 		switch v {
 		case command.Exit:
 			fmt.Println("접속을 종료합니다.")
 			cancel()
-			break
-		case command.Echo:
-			func(msg string) {
+		case command.Color:
+			if len(args) == 0 {
 				mutex.RLock()
-				defer mutex.RUnlock()
-				if err := c.SendMessage(authToken, msg); err != nil {
-					logger.Err(
-						"api request failed",
-						"method", "Message",
-						"err", err,
-					)
+				fmt.Println("현재 색상 모드:", colorMode)
+				mutex.RUnlock()
+				return nil
+			}
+			mode, ok := markup.ParseMode(args[0])
+			if !ok {
+				fmt.Println("알 수 없는 색상 모드입니다. (끔, 16, 256, 트루):", args[0])
+				return errors.New(fmt.Sprintln("unknown color mode", args[0]))
+			}
+			func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				colorMode = mode
+			}()
+			fmt.Println("색상 모드를 변경했습니다:", mode)
+		case command.Alias:
+			if len(args) == 0 {
+				fmt.Println("사용법: <별칭> [명령어...] 별칭")
+				return errors.New("alias name missing")
+			}
+			if len(args) == 1 {
+				if engine.RemoveAlias(args[0]) {
+					fmt.Println("별칭을 지웠습니다:", args[0])
 				}
-			}(input)
+				return nil
+			}
+			engine.SetAlias(args[0], strings.Join(args[1:], whitespace))
+			fmt.Println("별칭을 등록했습니다:", args[0])
+		case command.Lua:
+			if err := engine.Run(strings.Join(args, whitespace)); err != nil {
+				fmt.Println("스크립트를 실행하는 도중 에러가 발생했습니다.:", err)
+				return err
+			}
+		}
+		return nil
+	}
+
+	handle := func(input string) error {
+		// the server editor takes lines as they are typed
+		if atomic.LoadInt32(&editing) == 1 {
+			return send(input)
+		}
+		expanded, err := engine.Expand(input)
+		if err != nil {
+			fmt.Println("별칭을 처리하는 도중 에러가 발생했습니다.:", err)
+			return err
+		}
+		for _, line := range expanded {
+			if err := execute(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for ctx.Err() == nil {
+		select {
+		case r := <-requests:
+			r.result <- handle(r.input)
+		case input := <-engine.Commands():
+			// what scripts send would end up in the text of the editor
+			if atomic.LoadInt32(&editing) == 1 {
+				fmt.Println("편집 중이라 스크립트의 명령을 보내지 않았습니다:", input)
+				continue
+			}
+			_ = handle(input)
+		case <-ctx.Done():
 		}
 	}
 
@@ -211,4 +489,114 @@ func main() {
 
 	time.Sleep(time.Second)
 	logger.Info("end")
+	return int(atomic.LoadInt32(&exitCode))
+}
+
+func loadBatch(path string) ([]batch.Step, error) {
+	if path == "-" {
+		return batch.Parse(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return batch.Parse(f)
+}
+
+func dataDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".mud")
+}
+
+func defaultInitScript() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	path := filepath.Join(dir, "init.lua")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// historyFile keeps input history across runs. An empty path keeps it in memory only.
+func historyFile() string {
+	dir := dataDir()
+	if dir == "" {
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "history")
+}
+
+// onEvent keeps the client up to date with what the server tells about the player. editing is
+// set to 1 while the server editor is open.
+func onEvent(completer *complete.Completer, rl *readline.Instance, editing *int32, kind event.Kind,
+	data []byte, logger logging.Logger) {
+	switch kind {
+	case event.StatsKind:
+		var stats event.Stats
+		if err := event.Decode(data, &stats); err != nil {
+			logger.Warn(
+				"event decoding failed",
+				"kind", kind,
+				"err", err,
+			)
+			return
+		}
+		if rl != nil {
+			rl.SetPrompt(fmt.Sprintf("[체력 %d/%d 마력 %d/%d 기력 %d/%d] > ",
+				stats.HP, stats.MaxHP, stats.MP, stats.MaxMP, stats.Stamina, stats.MaxStamina))
+			rl.Refresh()
+		}
+	case event.CommandsKind:
+		var commands event.Commands
+		if err := event.Decode(data, &commands); err != nil {
+			logger.Warn(
+				"event decoding failed",
+				"kind", kind,
+				"err", err,
+			)
+			return
+		}
+		completer.Set("server", commands.Words)
+	case event.RoomKind:
+		var room event.Room
+		if err := event.Decode(data, &room); err != nil {
+			logger.Warn(
+				"event decoding failed",
+				"kind", kind,
+				"err", err,
+			)
+			return
+		}
+		completer.Set("exits", room.Exits)
+		completer.Set("players", room.Players)
+		completer.Set("mobs", room.Mobs)
+		completer.Set("items", room.Items)
+	case event.EditorKind:
+		var editor event.Editor
+		if err := event.Decode(data, &editor); err != nil {
+			logger.Warn(
+				"event decoding failed",
+				"kind", kind,
+				"err", err,
+			)
+			return
+		}
+		var open int32
+		if editor.Open {
+			open = 1
+		}
+		atomic.StoreInt32(editing, open)
+	}
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/zrma/mud/client/record"
+	"github.com/zrma/mud/markup"
+)
+
+func replay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	speed := flags.Float64("speed", 1, "playback speed, 0 plays everything at once")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: client replay [-speed n] <recording>")
+		return 2
+	}
+
+	entries, err := record.Load(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "녹화 파일을 읽을 수 없습니다.:", err)
+		return 1
+	}
+
+	renderer := markup.NewRenderer(markup.Color16)
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		renderer = markup.NewRenderer(markup.None)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	if err := record.Replay(ctx, entries, *speed, func(entry record.Entry) error {
+		switch entry.Direction {
+		case record.Sent:
+			fmt.Println(">", entry.Msg)
+		case record.Received:
+			fmt.Println(markup.Render(entry.Msg, renderer))
+		}
+		return nil
+	}); err != nil && err != context.Canceled {
+		fmt.Fprintln(os.Stderr, "재생 중 에러가 발생했습니다.:", err)
+		return 1
+	}
+	return 0
+}
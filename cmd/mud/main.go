@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zrma/mud/role"
+	"github.com/zrma/mud/server/script"
+	"github.com/zrma/mud/store"
+	"github.com/zrma/mud/world"
+)
+
+type subcommand struct {
+	usage string
+	run   func(args []string) int
+}
+
+var subcommands = map[string]subcommand{
+	"validate-world": {
+		usage: "check area files without starting the server",
+		run:   validateWorld,
+	},
+	"migrate": {
+		usage: "upgrade saved data to the current version",
+		run:   migrate,
+	},
+	"role": {
+		usage: "give an account a role: player, builder, moderator or admin",
+		run:   setRole,
+	},
+	"password": {
+		usage: "set the password of an account, read from the first line of stdin",
+		run:   setPassword,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := subcommands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: mud <command> [arguments]")
+	for name, cmd := range subcommands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, cmd.usage)
+	}
+}
+
+func validateWorld(args []string) int {
+	flags := flag.NewFlagSet("validate-world", flag.ExitOnError)
+	dir := flags.String("dir", "areas", "directory of area files")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	w, err := world.Load(*dir)
+	if err == nil {
+		err = script.CheckWorld(w)
+	}
+	if err != nil {
+		if problems, ok := err.(world.ValidationError); ok {
+			for _, p := range problems {
+				fmt.Println(p)
+			}
+			fmt.Printf("%d problem(s) found\n", len(problems))
+			return 1
+		}
+		fmt.Println(err)
+		return 1
+	}
+
+	fmt.Printf("ok: %d areas, %d rooms, %d items, %d mobs, %d quests, %d socials, %d scripts\n",
+		len(w.Areas), len(w.Rooms), len(w.Items), len(w.Mobs), len(w.Quests), len(w.Socials), len(w.Scripts))
+	return 0
+}
+
+func migrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	spec := flags.String("store", "file:data", "saved data, file:<dir> or bolt:<file>")
+	write := flags.Bool("write", false, "save the upgraded records instead of only reporting them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	st, err := store.Open(*spec)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer st.Close()
+
+	raw, ok := st.(store.Raw)
+	if !ok {
+		fmt.Printf("%s can't be migrated\n", *spec)
+		return 1
+	}
+	changes, err := store.Migrate(raw, !*write)
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	switch {
+	case len(changes) == 0:
+		fmt.Printf("ok: everything is at version %d\n", store.Version)
+	case *write:
+		fmt.Printf("%d record(s) upgraded to version %d\n", len(changes), store.Version)
+	default:
+		fmt.Printf("%d record(s) would be upgraded to version %d, run with -write to save them\n",
+			len(changes), store.Version)
+	}
+	return 0
+}
+
+func setRole(args []string) int {
+	flags := flag.NewFlagSet("role", flag.ExitOnError)
+	spec := flags.String("store", "file:data", "saved data, file:<dir> or bolt:<file>")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Println("usage: mud role [-store spec] <name> <player|builder|moderator|admin>")
+		return 2
+	}
+	name := flags.Arg(0)
+	r, err := role.Parse(flags.Arg(1))
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	st, err := store.Open(*spec)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer st.Close()
+
+	// An account that never logged in is created, so that it starts out with the role.
+	a, err := st.LoadAccount(name)
+	switch err {
+	case nil:
+	case store.ErrNotFound:
+		a = &store.Account{Name: name, Created: time.Now()}
+	default:
+		fmt.Println(err)
+		return 1
+	}
+	a.Role = r
+	if err := st.SaveAccount(a); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	fmt.Printf("%s has the role %s from the next login\n", name, r)
+	if r != role.Player && a.Password == "" {
+		fmt.Printf("%s has no password and plays as a player until it gets one with mud password\n", name)
+	}
+	return 0
+}
+
+func setPassword(args []string) int {
+	flags := flag.NewFlagSet("password", flag.ExitOnError)
+	spec := flags.String("store", "file:data", "saved data, file:<dir> or bolt:<file>")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Println("usage: mud password [-store spec] <name> < password")
+		return 2
+	}
+	name := flags.Arg(0)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		fmt.Println(err)
+		return 1
+	}
+	hash, err := store.HashPassword(strings.TrimRight(line, "\r\n"))
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	st, err := store.Open(*spec)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer st.Close()
+
+	a, err := st.LoadAccount(name)
+	switch err {
+	case nil:
+	case store.ErrNotFound:
+		a = &store.Account{Name: name, Created: time.Now()}
+	default:
+		fmt.Println(err)
+		return 1
+	}
+	a.Password = hash
+	if err := st.SaveAccount(a); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	fmt.Printf("%s has a new password from the next login\n", name)
+	return 0
+}
@@ -1,11 +1,21 @@
 package main
 
 import (
+	_ "expvar"
+	"flag"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/zrma/mud/logging"
 	"github.com/zrma/mud/server"
+	"github.com/zrma/mud/server/audit"
+	"github.com/zrma/mud/server/game"
+	"github.com/zrma/mud/server/limit"
+	"github.com/zrma/mud/store"
 )
 
 func main() {
@@ -27,6 +37,23 @@ func main() {
 		logLevel = logging.Dev
 	}
 
+	worldDir := flag.String("world", "areas", "directory of area files")
+	seed := flag.Int64("seed", 0, "seed of the random rolls, 0 picks one from the clock")
+	tick := flag.Duration("tick", 100*time.Millisecond, "interval of the game loop")
+	storeSpec := flag.String("store", "file:data", "where to save players and the world, file:<dir> or bolt:<file>")
+	save := flag.Duration("save", 5*time.Minute, "interval of the periodic save")
+	limits := limit.DefaultConfig
+	flag.Float64Var(&limits.SessionRate, "rate", limits.SessionRate, "requests per second of a session, 0 for no limit")
+	flag.IntVar(&limits.SessionBurst, "burst", limits.SessionBurst, "requests a session may make at once, at least 1")
+	flag.Float64Var(&limits.AddressRate, "ip-rate", limits.AddressRate, "requests per second from an address, 0 for no limit")
+	flag.IntVar(&limits.AddressBurst, "ip-burst", limits.AddressBurst, "requests an address may make at once, at least 1")
+	flag.IntVar(&limits.Repeats, "repeats", limits.Repeats, "times a player may say the same thing in a row, 0 for no limit")
+	flag.DurationVar(&limits.RepeatWindow, "repeat-window", limits.RepeatWindow, "how far apart messages still count as repeated")
+	flag.DurationVar(&limits.MuteFor, "mute", limits.MuteFor, "how long a player repeating themselves is muted")
+	auditPath := flag.String("audit", "data/audit.log", "file the actions of admins are appended to")
+	metrics := flag.String("metrics", "", "address to serve metrics on at /debug/vars, empty for none")
+	flag.Parse()
+
 	logger, err := logging.NewLogger(logLevel)
 	if err != nil {
 		log.Fatalln(err)
@@ -36,6 +63,63 @@ func main() {
 		"method", "main",
 	)
 
-	s := server.New(logger, 5555)
+	st, err := store.Open(*storeSpec)
+	if err != nil {
+		logger.Fatal(
+			"store opening failed",
+			"store", *storeSpec,
+			"err", err,
+		)
+	}
+	defer st.Close()
+
+	auditLog, err := audit.Open(*auditPath)
+	if err != nil {
+		logger.Fatal(
+			"audit log opening failed",
+			"path", *auditPath,
+			"err", err,
+		)
+	}
+	defer auditLog.Close()
+
+	s, err := server.New(logger, "", 5555, game.Config{
+		WorldDir:     *worldDir,
+		Seed:         *seed,
+		TickRate:     *tick,
+		Store:        st,
+		SaveInterval: *save,
+		Limits:       limits,
+		Audit:        auditLog,
+	})
+	if err != nil {
+		logger.Fatal(
+			"server initializing failed",
+			"err", err,
+		)
+	}
+
+	if *metrics != "" {
+		go func() {
+			if err := http.ListenAndServe(*metrics, nil); err != nil {
+				logger.Err(
+					"metrics serving failed",
+					"addr", *metrics,
+					"err", err,
+				)
+			}
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		logger.Info(
+			"shutting down",
+			"method", "main",
+		)
+		s.Shutdown()
+	}()
 	s.Run()
 }
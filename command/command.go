@@ -9,7 +9,9 @@ type OpCode int
 
 const (
 	Exit OpCode = iota
-	Echo
+	Color
+	Alias
+	Lua
 )
 
 type command struct {
@@ -44,6 +46,22 @@ var _ = Register("끝", func() (o OpCode, e error) {
 	return Exit, nil
 })
 
-var _ = Register("말", func() (o OpCode, e error) {
-	return Echo, nil
+var _ = Register("색", func() (o OpCode, e error) {
+	return Color, nil
 })
+
+var _ = Register("별칭", func() (o OpCode, e error) {
+	return Alias, nil
+})
+
+var _ = Register("루아", func() (o OpCode, e error) {
+	return Lua, nil
+})
+
+func Words() []string {
+	words := make([]string, 0, len(commands))
+	for word := range commands {
+		words = append(words, word)
+	}
+	return words
+}
@@ -0,0 +1,73 @@
+package event
+
+import "encoding/json"
+
+// Kind names the payload of a structured event pushed along with ReceiveReply.msg.
+type Kind string
+
+const (
+	RoomKind     Kind = "room"
+	TargetKind   Kind = "target"
+	StatsKind    Kind = "stats"
+	CommandsKind Kind = "commands"
+	EditorKind   Kind = "editor"
+)
+
+// Room describes what the player can currently see, for completion and rich clients.
+type Room struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Exits       []string `json:"exits,omitempty"`
+	Players     []string `json:"players,omitempty"`
+	Mobs        []string `json:"mobs,omitempty"`
+	Items       []string `json:"items,omitempty"`
+}
+
+func Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Target is the detailed view of a single thing the player examined.
+type Target struct {
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Contents    []string `json:"contents,omitempty"`
+}
+
+// Commands lists the command words the server understands.
+type Commands struct {
+	Words []string `json:"words"`
+}
+
+// Editor tells whether the server takes the lines of the player as text for its editor, in which
+// case clients send them as typed, without expanding aliases or running their own commands.
+type Editor struct {
+	Open bool `json:"open"`
+}
+
+// Stats carries the numbers of a character for status bars and the score sheet.
+type Stats struct {
+	Name       string `json:"name"`
+	Level      int    `json:"level"`
+	Exp        int    `json:"exp"`
+	ExpNext    int    `json:"exp_next"`
+	HP         int    `json:"hp"`
+	MaxHP      int    `json:"max_hp"`
+	MP         int    `json:"mp"`
+	MaxMP      int    `json:"max_mp"`
+	Stamina    int    `json:"stamina"`
+	MaxStamina int    `json:"max_stamina"`
+	Gold       int    `json:"gold"`
+
+	Strength     int `json:"strength"`
+	Dexterity    int `json:"dexterity"`
+	Constitution int `json:"constitution"`
+	Intelligence int `json:"intelligence"`
+	Wisdom       int `json:"wisdom"`
+}
@@ -3,9 +3,12 @@ module github.com/zrma/mud
 go 1.13
 
 require (
+	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/golang/protobuf v1.3.2
 	github.com/pborman/uuid v1.2.0
+	github.com/yuin/gopher-lua v0.0.0-20190514113301-1cd887cd7036
+	go.etcd.io/bbolt v1.3.6
 	go.uber.org/zap v1.12.0
 	google.golang.org/grpc v1.24.0
 )
@@ -0,0 +1,112 @@
+package item
+
+import (
+	"errors"
+
+	"github.com/zrma/mud/world"
+)
+
+var (
+	ErrNoSlot       = errors.New("item can't be equipped")
+	ErrSlotOccupied = errors.New("slot is occupied")
+)
+
+// Inventory is what a character carries and has equipped.
+type Inventory struct {
+	Items     []*Item          `json:"items,omitempty"`
+	Equipment map[string]*Item `json:"equipment,omitempty"`
+}
+
+func NewInventory() *Inventory {
+	return &Inventory{Equipment: make(map[string]*Item)}
+}
+
+func (inv *Inventory) Bind(w *world.World) {
+	if inv.Equipment == nil {
+		inv.Equipment = make(map[string]*Item)
+	}
+	for _, i := range inv.Items {
+		i.Bind(w)
+	}
+	for _, i := range inv.Equipment {
+		i.Bind(w)
+	}
+}
+
+func (inv *Inventory) Add(i *Item) {
+	inv.Items = append(inv.Items, i)
+}
+
+func (inv *Inventory) Remove(word string) (*Item, bool) {
+	var found *Item
+	inv.Items, found = remove(inv.Items, word)
+	return found, found != nil
+}
+
+func (inv *Inventory) Find(word string) (*Item, bool) {
+	return Find(inv.Items, word)
+}
+
+// FindAny looks in the carried items first and then in the equipment.
+func (inv *Inventory) FindAny(word string) (*Item, bool) {
+	if i, ok := inv.Find(word); ok {
+		return i, true
+	}
+	for _, slot := range world.Slots {
+		if i, ok := inv.Equipment[slot]; ok && i.Matches(word) {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+// Count tells how many items of a template are carried, equipped or inside those.
+func (inv *Inventory) Count(template string) int {
+	n := count(inv.Items, template)
+	for _, i := range inv.Equipment {
+		n += count([]*Item{i}, template)
+	}
+	return n
+}
+
+func count(items []*Item, template string) int {
+	n := 0
+	for _, i := range items {
+		if i.Template == template {
+			n++
+		}
+		n += count(i.Contents, template)
+	}
+	return n
+}
+
+// Equip moves a carried item into its slot.
+func (inv *Inventory) Equip(word string) (*Item, error) {
+	i, ok := inv.Find(word)
+	if !ok {
+		return nil, nil
+	}
+	slot := i.Slot()
+	if slot == "" {
+		return i, ErrNoSlot
+	}
+	if _, ok := inv.Equipment[slot]; ok {
+		return i, ErrSlotOccupied
+	}
+
+	inv.Remove(word)
+	inv.Equipment[slot] = i
+	return i, nil
+}
+
+// Unequip moves an equipped item back to the carried items.
+func (inv *Inventory) Unequip(word string) (*Item, bool) {
+	for _, slot := range world.Slots {
+		if i, ok := inv.Equipment[slot]; ok && i.Matches(word) {
+			delete(inv.Equipment, slot)
+			inv.Add(i)
+			return i, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,158 @@
+package item
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pborman/uuid"
+
+	"github.com/zrma/mud/world"
+)
+
+var (
+	ErrNotContainer = errors.New("not a container")
+	ErrFull         = errors.New("container is full")
+	ErrSelf         = errors.New("can't put a container into itself")
+)
+
+// Item is an instance of a template. Its ID is stable for its whole life so that it can be
+// saved, traded and referred to by logs.
+type Item struct {
+	ID       string  `json:"id"`
+	Template string  `json:"template"`
+	Contents []*Item `json:"contents,omitempty"`
+
+	t *world.ItemTemplate
+}
+
+func New(t *world.ItemTemplate) *Item {
+	return &Item{
+		ID:       uuid.New(),
+		Template: t.ID,
+		t:        t,
+	}
+}
+
+// Bind resolves the template of the item and its contents against w. It has to be called after
+// loading saved items and after the world was reloaded.
+func (i *Item) Bind(w *world.World) {
+	i.t = w.Items[i.Template]
+	for _, c := range i.Contents {
+		c.Bind(w)
+	}
+}
+
+func (i *Item) Name() string {
+	if i.t == nil {
+		return i.Template
+	}
+	return i.t.Name
+}
+
+func (i *Item) Description() string {
+	if i.t == nil {
+		return ""
+	}
+	return i.t.Description
+}
+
+func (i *Item) Slot() string {
+	if i.t == nil {
+		return ""
+	}
+	return i.t.Slot
+}
+
+func (i *Item) Damage() int {
+	if i.t == nil {
+		return 0
+	}
+	return i.t.Damage
+}
+
+func (i *Item) Armor() int {
+	if i.t == nil {
+		return 0
+	}
+	return i.t.Armor
+}
+
+func (i *Item) Value() int {
+	if i.t == nil {
+		return 0
+	}
+	return i.t.Value
+}
+
+func (i *Item) Fixed() bool {
+	return i.t != nil && i.t.Fixed
+}
+
+func (i *Item) IsContainer() bool {
+	return i.t != nil && i.t.Container
+}
+
+// Matches tells if a word typed by a player refers to the item. No item matches an empty word.
+func (i *Item) Matches(word string) bool {
+	if word == "" {
+		return false
+	}
+	if i.t == nil {
+		return word == i.Template
+	}
+	if word == i.t.Name || strings.HasPrefix(i.t.Name, word) {
+		return true
+	}
+	for _, k := range i.t.Keywords {
+		if word == k {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Item) Put(other *Item) error {
+	if !i.IsContainer() {
+		return ErrNotContainer
+	}
+	if other == i {
+		return ErrSelf
+	}
+	if i.t.Capacity > 0 && len(i.Contents) >= i.t.Capacity {
+		return ErrFull
+	}
+	i.Contents = append(i.Contents, other)
+	return nil
+}
+
+func (i *Item) Take(word string) (*Item, bool) {
+	var found *Item
+	i.Contents, found = remove(i.Contents, word)
+	return found, found != nil
+}
+
+func Find(items []*Item, word string) (*Item, bool) {
+	for _, i := range items {
+		if i.Matches(word) {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+func Names(items []*Item) []string {
+	names := make([]string, 0, len(items))
+	for _, i := range items {
+		names = append(names, i.Name())
+	}
+	return names
+}
+
+func remove(items []*Item, word string) ([]*Item, *Item) {
+	for idx, i := range items {
+		if i.Matches(word) {
+			return append(items[:idx:idx], items[idx+1:]...), i
+		}
+	}
+	return items, nil
+}
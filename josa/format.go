@@ -0,0 +1,43 @@
+package josa
+
+import (
+	"regexp"
+	"strings"
+)
+
+var placeholder = regexp.MustCompile(`\{\{|\{([^{}:]+)(?::([^{}]*))?\}`)
+
+// Format fills the placeholders of a template: {actor} becomes the value given for actor and
+// {actor:이/가} the value followed by the particle that fits it. kv lists names and values in
+// turn, the way log fields are given:
+//
+//	Format("{actor:이/가} {item:을/를} 주웠습니다.", "actor", "철수", "item", "빵")
+//
+// Placeholders without a value are left as they are, so color markup like {r} and escaped
+// braces pass through.
+func Format(template string, kv ...string) string {
+	values := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		values[kv[i]] = kv[i+1]
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range placeholder.FindAllStringSubmatchIndex(template, -1) {
+		if m[2] < 0 {
+			continue // {{
+		}
+		v, ok := values[template[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+		if m[4] >= 0 && m[4] < m[5] {
+			v = Attach(v, template[m[4]:m[5]])
+		}
+		sb.WriteString(template[last:m[0]])
+		sb.WriteString(v)
+		last = m[1]
+	}
+	sb.WriteString(template[last:])
+	return sb.String()
+}
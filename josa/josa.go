@@ -0,0 +1,176 @@
+package josa
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	hangulFirst = '가'
+	hangulLast  = '힣'
+	finals      = 28 // number of final consonants, including none
+	rieul       = 8  // index of ㄹ among the final consonants
+)
+
+// pairs are the particles that change with the word before them, the form after a consonant
+// first.
+var pairs = [][2]string{
+	{"이", "가"},
+	{"을", "를"},
+	{"은", "는"},
+	{"과", "와"},
+	{"아", "야"},
+	{"으로", "로"},
+	{"이랑", "랑"},
+	{"이나", "나"},
+	{"이라", "라"},
+}
+
+// HasBatchim tells whether word, as it is read, ends in a consonant. Numbers are read the way
+// they are said, "3" as 삼 and "10" as 십, and Latin words the way they are usually written in
+// Hangul, "Tom" as 톰 and "Alice" as 앨리스. Anything else counts as ending in a vowel.
+func HasBatchim(word string) bool {
+	final, _ := lastSound(word)
+	return final
+}
+
+// Pick chooses the form of a particle that fits word. The particle can be given as a pair like
+// "이/가", or as one of its forms like "가". Pairs this package doesn't know are taken with the
+// form after a consonant first. Particles that never change, like "에게", are returned as they
+// are.
+func Pick(word, particle string) string {
+	consonant, vowel, ok := pair(particle)
+	if !ok {
+		return particle
+	}
+	final, l := lastSound(word)
+	// ㄹ takes 로 like a vowel does: 길로, 칼로
+	if !final || (l && consonant == "으로") {
+		return vowel
+	}
+	return consonant
+}
+
+// Attach returns word followed by the fitting form of the particle, like "철수가".
+func Attach(word, particle string) string {
+	return word + Pick(word, particle)
+}
+
+func pair(particle string) (string, string, bool) {
+	forms := strings.Split(particle, "/")
+	for _, p := range pairs {
+		for _, f := range forms {
+			if f == p[0] || f == p[1] {
+				return p[0], p[1], true
+			}
+		}
+	}
+	if len(forms) == 2 {
+		return forms[0], forms[1], true
+	}
+	return "", "", false
+}
+
+// lastSound tells whether word ends in a consonant when read, and whether that consonant is ㄹ.
+func lastSound(word string) (final bool, l bool) {
+	runes := []rune(strings.TrimRightFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+	if len(runes) == 0 {
+		return false, false
+	}
+
+	last := runes[len(runes)-1]
+	switch {
+	case last >= hangulFirst && last <= hangulLast:
+		jong := (last - hangulFirst) % finals
+		return jong != 0, jong == rieul
+	case last >= '0' && last <= '9':
+		return number(runes)
+	case last < unicode.MaxASCII && unicode.IsLetter(last):
+		return latin(runes)
+	}
+	return false, false
+}
+
+// digits are how the digits are read, 영 through 구.
+var digits = [10]struct{ final, l bool }{
+	{true, false},  // 영
+	{true, true},   // 일
+	{false, false}, // 이
+	{true, false},  // 삼
+	{false, false}, // 사
+	{false, false}, // 오
+	{true, false},  // 육
+	{true, true},   // 칠
+	{true, true},   // 팔
+	{false, false}, // 구
+}
+
+// number reads the last part of a number: the last digit, or the unit of the trailing zeros,
+// 십, 백, 천, 만, 억 and 조.
+func number(runes []rune) (bool, bool) {
+	zeros := 0
+	i := len(runes) - 1
+	for ; i >= 0 && runes[i] == '0'; i-- {
+		zeros++
+	}
+	if i < 0 || runes[i] < '1' || runes[i] > '9' || zeros == 0 {
+		d := digits[runes[len(runes)-1]-'0']
+		return d.final, d.l
+	}
+
+	switch {
+	case zeros >= 12:
+		return false, false // 조
+	case zeros >= 8:
+		return true, false // 억
+	case zeros >= 4:
+		return true, false // 만
+	case zeros == 3:
+		return true, false // 천
+	case zeros == 2:
+		return true, false // 백
+	}
+	return true, false // 십
+}
+
+// latin guesses the Hangul spelling of a Latin word. Abbreviations are read letter by letter,
+// where only L, M, N and R end in a consonant: 엘, 엠, 엔, 알.
+func latin(runes []rune) (bool, bool) {
+	start := len(runes)
+	for start > 0 && runes[start-1] < unicode.MaxASCII && unicode.IsLetter(runes[start-1]) {
+		start--
+	}
+	word := string(runes[start:])
+
+	if strings.ToUpper(word) == word {
+		switch word[len(word)-1] {
+		case 'L', 'R':
+			return true, true
+		case 'M', 'N':
+			return true, false
+		}
+		return false, false
+	}
+
+	word = strings.ToLower(word)
+	last := word[len(word)-1]
+	var before byte
+	if len(word) > 1 {
+		before = word[len(word)-2]
+	}
+	switch {
+	case last == 'l':
+		return true, true
+	case last == 'm' || last == 'n':
+		return true, false
+	case last == 'g' && before == 'n':
+		return true, false
+	case last == 'k' && before == 'c':
+		return true, false
+	case strings.IndexByte("bkpt", last) >= 0 && strings.IndexByte("aeiou", before) >= 0:
+		return true, false
+	}
+	return false, false
+}
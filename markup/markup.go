@@ -0,0 +1,169 @@
+package markup
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Color codes are written inline as {r}, {G}, {x} and so on. Lower case letters are the normal
+// colors, upper case letters the bright ones, {x} resets and {#rrggbb} picks an arbitrary color
+// that is downsampled by the renderer when needed. {{ and }} produce literal braces.
+const (
+	openBrace  = '{'
+	closeBrace = '}'
+	reset      = 'x'
+)
+
+type Color struct {
+	R, G, B uint8
+	Index   int // position in the 16 color palette, -1 for arbitrary colors
+}
+
+var palette = [16]Color{
+	{0, 0, 0, 0},
+	{205, 0, 0, 1},
+	{0, 205, 0, 2},
+	{205, 205, 0, 3},
+	{0, 0, 238, 4},
+	{205, 0, 205, 5},
+	{0, 205, 205, 6},
+	{229, 229, 229, 7},
+	{127, 127, 127, 8},
+	{255, 0, 0, 9},
+	{0, 255, 0, 10},
+	{255, 255, 0, 11},
+	{92, 92, 255, 12},
+	{255, 0, 255, 13},
+	{0, 255, 255, 14},
+	{255, 255, 255, 15},
+}
+
+var letters = map[rune]int{
+	'k': 0, 'r': 1, 'g': 2, 'y': 3, 'b': 4, 'm': 5, 'c': 6, 'w': 7,
+	'K': 8, 'R': 9, 'G': 10, 'Y': 11, 'B': 12, 'M': 13, 'C': 14, 'W': 15,
+}
+
+// Renderer turns parsed markup into the representation of a single front-end.
+type Renderer interface {
+	Text(s string) string
+	Color(c Color) string
+	Reset() string
+}
+
+func Render(s string, r Renderer) string {
+	var sb strings.Builder
+	colored := false
+	for _, t := range parse(s) {
+		switch {
+		case t.reset:
+			if colored {
+				sb.WriteString(r.Reset())
+				colored = false
+			}
+		case t.color != nil:
+			if colored {
+				sb.WriteString(r.Reset())
+			}
+			sb.WriteString(r.Color(*t.color))
+			colored = true
+		default:
+			sb.WriteString(r.Text(t.text))
+		}
+	}
+	if colored {
+		sb.WriteString(r.Reset())
+	}
+	return sb.String()
+}
+
+// Escape quotes braces so that user supplied text is shown verbatim.
+func Escape(s string) string {
+	s = strings.Replace(s, "{", "{{", -1)
+	return strings.Replace(s, "}", "}}", -1)
+}
+
+func Strip(s string) string {
+	return Render(s, Plain{})
+}
+
+type token struct {
+	text  string
+	color *Color
+	reset bool
+}
+
+func parse(s string) []token {
+	var tokens []token
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, token{text: text.String()})
+			text.Reset()
+		}
+	}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		if ch == closeBrace && i+1 < len(runes) && runes[i+1] == closeBrace {
+			text.WriteRune(closeBrace)
+			i++
+			continue
+		}
+		if ch != openBrace {
+			text.WriteRune(ch)
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == openBrace {
+			text.WriteRune(openBrace)
+			i++
+			continue
+		}
+
+		end := -1
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] == closeBrace {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			text.WriteRune(ch)
+			continue
+		}
+
+		t, ok := code(string(runes[i+1 : end]))
+		if !ok {
+			text.WriteRune(ch)
+			continue
+		}
+		flush()
+		tokens = append(tokens, t)
+		i = end
+	}
+	flush()
+	return tokens
+}
+
+func code(s string) (token, bool) {
+	if s == string(reset) {
+		return token{reset: true}, true
+	}
+	if r := []rune(s); len(r) == 1 {
+		idx, ok := letters[r[0]]
+		if !ok {
+			return token{}, false
+		}
+		c := palette[idx]
+		return token{color: &c}, true
+	}
+	if len(s) == 7 && s[0] == '#' {
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return token{}, false
+		}
+		c := Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), Index: -1}
+		return token{color: &c}, true
+	}
+	return token{}, false
+}
@@ -0,0 +1,150 @@
+package markup
+
+import (
+	"fmt"
+	"html"
+)
+
+type Mode int
+
+const (
+	None Mode = 0 + iota
+	Color16
+	Color256
+	TrueColor
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Color16:
+		return "16"
+	case Color256:
+		return "256"
+	case TrueColor:
+		return "truecolor"
+	case None:
+		fallthrough
+	default:
+		return "none"
+	}
+}
+
+func ParseMode(s string) (Mode, bool) {
+	switch s {
+	case "끔", "none", "off":
+		return None, true
+	case "켬", "16", "on":
+		return Color16, true
+	case "256":
+		return Color256, true
+	case "트루", "truecolor", "24bit":
+		return TrueColor, true
+	}
+	return None, false
+}
+
+// ANSI renders escape sequences for terminals and telnet.
+type ANSI struct {
+	Mode Mode
+}
+
+func NewRenderer(mode Mode) Renderer {
+	if mode == None {
+		return Plain{}
+	}
+	return ANSI{Mode: mode}
+}
+
+func (a ANSI) Text(s string) string {
+	return s
+}
+
+func (a ANSI) Color(c Color) string {
+	switch a.Mode {
+	case TrueColor:
+		if c.Index >= 0 {
+			return ansi16(c.Index)
+		}
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	case Color256:
+		if c.Index >= 0 {
+			return ansi16(c.Index)
+		}
+		return fmt.Sprintf("\x1b[38;5;%dm", cube(c))
+	case Color16:
+		if c.Index >= 0 {
+			return ansi16(c.Index)
+		}
+		return ansi16(nearest(c))
+	}
+	return ""
+}
+
+func (a ANSI) Reset() string {
+	if a.Mode == None {
+		return ""
+	}
+	return "\x1b[0m"
+}
+
+func ansi16(idx int) string {
+	if idx < 8 {
+		return fmt.Sprintf("\x1b[%dm", 30+idx)
+	}
+	return fmt.Sprintf("\x1b[%dm", 90+idx-8)
+}
+
+// cube maps a color onto the 6x6x6 cube of the xterm 256 color palette.
+func cube(c Color) int {
+	level := func(v uint8) int {
+		if v < 48 {
+			return 0
+		}
+		if v < 115 {
+			return 1
+		}
+		return (int(v) - 35) / 40
+	}
+	return 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+}
+
+func nearest(c Color) int {
+	best, dist := 0, -1
+	for _, p := range palette {
+		dr, dg, db := int(c.R)-int(p.R), int(c.G)-int(p.G), int(c.B)-int(p.B)
+		if d := dr*dr + dg*dg + db*db; dist < 0 || d < dist {
+			best, dist = p.Index, d
+		}
+	}
+	return best
+}
+
+// HTML renders spans for web front-ends such as a WebSocket gateway.
+type HTML struct{}
+
+func (HTML) Text(s string) string {
+	return html.EscapeString(s)
+}
+
+func (HTML) Color(c Color) string {
+	return fmt.Sprintf(`<span style="color:#%02x%02x%02x">`, c.R, c.G, c.B)
+}
+
+func (HTML) Reset() string {
+	return "</span>"
+}
+
+// Plain drops every color code, for clients that disabled color.
+type Plain struct{}
+
+func (Plain) Text(s string) string {
+	return s
+}
+
+func (Plain) Color(Color) string {
+	return ""
+}
+
+func (Plain) Reset() string {
+	return ""
+}
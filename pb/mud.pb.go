@@ -28,6 +28,7 @@ const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 type PingRequest struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Token                string   `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Password             string   `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -72,6 +73,13 @@ func (m *PingRequest) GetToken() string {
 	return ""
 }
 
+func (m *PingRequest) GetPassword() string {
+	if m != nil {
+		return m.Password
+	}
+	return ""
+}
+
 // The response ping containing message and token
 type PingReply struct {
 	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -120,10 +128,14 @@ func (m *PingReply) GetToken() string {
 	return ""
 }
 
-// The request message
+// The request message, sent to a player when target is set and to a channel otherwise
 type MessageRequest struct {
-	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
-	Msg                  string   `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Msg   string `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	// The channel to talk on, 잡담 when empty
+	Channel string `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	// The name of the player to tell
+	Target               string   `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -168,6 +180,20 @@ func (m *MessageRequest) GetMsg() string {
 	return ""
 }
 
+func (m *MessageRequest) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *MessageRequest) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
 // The response message
 type MessageReply struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -242,7 +268,11 @@ func (m *ReceiveRequest) GetToken() string {
 
 // The response message stream
 type ReceiveReply struct {
-	Msg                  string   `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	Msg string `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	// The kind of structured event carried in data, empty for plain messages
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// JSON encoded event payload for rich clients
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -280,6 +310,262 @@ func (m *ReceiveReply) GetMsg() string {
 	return ""
 }
 
+func (m *ReceiveReply) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *ReceiveReply) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// The request command containing a whole input line
+type CommandRequest struct {
+	Token                string   `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Line                 string   `protobuf:"bytes,2,opt,name=line,proto3" json:"line,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommandRequest) Reset()         { *m = CommandRequest{} }
+func (m *CommandRequest) String() string { return proto.CompactTextString(m) }
+func (*CommandRequest) ProtoMessage()    {}
+func (*CommandRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_332afdaf9af33408, []int{6}
+}
+
+func (m *CommandRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommandRequest.Unmarshal(m, b)
+}
+func (m *CommandRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommandRequest.Marshal(b, m, deterministic)
+}
+func (m *CommandRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommandRequest.Merge(m, src)
+}
+func (m *CommandRequest) XXX_Size() int {
+	return xxx_messageInfo_CommandRequest.Size(m)
+}
+func (m *CommandRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommandRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommandRequest proto.InternalMessageInfo
+
+func (m *CommandRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *CommandRequest) GetLine() string {
+	if m != nil {
+		return m.Line
+	}
+	return ""
+}
+
+// The response command, output is delivered through the receive stream
+type CommandReply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommandReply) Reset()         { *m = CommandReply{} }
+func (m *CommandReply) String() string { return proto.CompactTextString(m) }
+func (*CommandReply) ProtoMessage()    {}
+func (*CommandReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_332afdaf9af33408, []int{7}
+}
+
+func (m *CommandReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommandReply.Unmarshal(m, b)
+}
+func (m *CommandReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommandReply.Marshal(b, m, deterministic)
+}
+func (m *CommandReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommandReply.Merge(m, src)
+}
+func (m *CommandReply) XXX_Size() int {
+	return xxx_messageInfo_CommandReply.Size(m)
+}
+func (m *CommandReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommandReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommandReply proto.InternalMessageInfo
+
+// The request history, of the room and every channel of the player when channel is empty
+type HistoryRequest struct {
+	Token   string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Channel string `protobuf:"bytes,2,opt,name=channel,proto3" json:"channel,omitempty"`
+	// The number of messages to return, the server picks one when zero
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HistoryRequest) Reset()         { *m = HistoryRequest{} }
+func (m *HistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*HistoryRequest) ProtoMessage()    {}
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_332afdaf9af33408, []int{8}
+}
+
+func (m *HistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryRequest.Unmarshal(m, b)
+}
+func (m *HistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryRequest.Marshal(b, m, deterministic)
+}
+func (m *HistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryRequest.Merge(m, src)
+}
+func (m *HistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_HistoryRequest.Size(m)
+}
+func (m *HistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryRequest proto.InternalMessageInfo
+
+func (m *HistoryRequest) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *HistoryRequest) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *HistoryRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// A message said on a channel, or in the room when channel is empty
+type HistoryEntry struct {
+	// Unix time in milliseconds
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Channel              string   `protobuf:"bytes,2,opt,name=channel,proto3" json:"channel,omitempty"`
+	Sender               string   `protobuf:"bytes,3,opt,name=sender,proto3" json:"sender,omitempty"`
+	Msg                  string   `protobuf:"bytes,4,opt,name=msg,proto3" json:"msg,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HistoryEntry) Reset()         { *m = HistoryEntry{} }
+func (m *HistoryEntry) String() string { return proto.CompactTextString(m) }
+func (*HistoryEntry) ProtoMessage()    {}
+func (*HistoryEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_332afdaf9af33408, []int{9}
+}
+
+func (m *HistoryEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryEntry.Unmarshal(m, b)
+}
+func (m *HistoryEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryEntry.Marshal(b, m, deterministic)
+}
+func (m *HistoryEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryEntry.Merge(m, src)
+}
+func (m *HistoryEntry) XXX_Size() int {
+	return xxx_messageInfo_HistoryEntry.Size(m)
+}
+func (m *HistoryEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryEntry proto.InternalMessageInfo
+
+func (m *HistoryEntry) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *HistoryEntry) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *HistoryEntry) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+// The response history, oldest message first
+type HistoryReply struct {
+	Entries              []*HistoryEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *HistoryReply) Reset()         { *m = HistoryReply{} }
+func (m *HistoryReply) String() string { return proto.CompactTextString(m) }
+func (*HistoryReply) ProtoMessage()    {}
+func (*HistoryReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_332afdaf9af33408, []int{10}
+}
+
+func (m *HistoryReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HistoryReply.Unmarshal(m, b)
+}
+func (m *HistoryReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HistoryReply.Marshal(b, m, deterministic)
+}
+func (m *HistoryReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HistoryReply.Merge(m, src)
+}
+func (m *HistoryReply) XXX_Size() int {
+	return xxx_messageInfo_HistoryReply.Size(m)
+}
+func (m *HistoryReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_HistoryReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HistoryReply proto.InternalMessageInfo
+
+func (m *HistoryReply) GetEntries() []*HistoryEntry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*PingRequest)(nil), "PingRequest")
 	proto.RegisterType((*PingReply)(nil), "PingReply")
@@ -287,27 +573,44 @@ func init() {
 	proto.RegisterType((*MessageReply)(nil), "MessageReply")
 	proto.RegisterType((*ReceiveRequest)(nil), "ReceiveRequest")
 	proto.RegisterType((*ReceiveReply)(nil), "ReceiveReply")
+	proto.RegisterType((*CommandRequest)(nil), "CommandRequest")
+	proto.RegisterType((*CommandReply)(nil), "CommandReply")
+	proto.RegisterType((*HistoryRequest)(nil), "HistoryRequest")
+	proto.RegisterType((*HistoryEntry)(nil), "HistoryEntry")
+	proto.RegisterType((*HistoryReply)(nil), "HistoryReply")
 }
 
 func init() { proto.RegisterFile("mud.proto", fileDescriptor_332afdaf9af33408) }
 
 var fileDescriptor_332afdaf9af33408 = []byte{
-	// 233 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0xcc, 0x2d, 0x4d, 0xd1,
-	0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x32, 0xe7, 0xe2, 0x0e, 0xc8, 0xcc, 0x4b, 0x0f, 0x4a, 0x2d,
-	0x2c, 0x4d, 0x2d, 0x2e, 0x11, 0x12, 0xe2, 0x62, 0xc9, 0x4b, 0xcc, 0x4d, 0x95, 0x60, 0x54, 0x60,
-	0xd4, 0xe0, 0x0c, 0x02, 0xb3, 0x85, 0x44, 0xb8, 0x58, 0x4b, 0xf2, 0xb3, 0x53, 0xf3, 0x24, 0x98,
-	0xc0, 0x82, 0x10, 0x8e, 0x92, 0x29, 0x17, 0x27, 0x44, 0x63, 0x41, 0x4e, 0x25, 0x09, 0xda, 0x2c,
-	0xb8, 0xf8, 0x7c, 0x53, 0x8b, 0x8b, 0x13, 0xd3, 0x53, 0x61, 0x56, 0xc2, 0xd5, 0x31, 0x22, 0xa9,
-	0x13, 0x12, 0xe0, 0x62, 0xce, 0x2d, 0x4e, 0x87, 0xea, 0x05, 0x31, 0x95, 0xf8, 0xb8, 0x78, 0xe0,
-	0x3a, 0x0b, 0x72, 0x2a, 0x95, 0xd4, 0xb8, 0xf8, 0x82, 0x52, 0x93, 0x53, 0x33, 0xcb, 0xf0, 0x9b,
-	0xa4, 0xa4, 0xc0, 0xc5, 0x03, 0x57, 0x07, 0x72, 0x2b, 0xd4, 0x64, 0x46, 0xb8, 0xc9, 0x46, 0xad,
-	0x8c, 0x5c, 0xcc, 0xbe, 0xa5, 0x29, 0x42, 0x4a, 0x5c, 0x2c, 0x20, 0x2f, 0x09, 0xf1, 0xe8, 0x21,
-	0x05, 0x89, 0x14, 0x97, 0x1e, 0xdc, 0x9f, 0x4a, 0x0c, 0x42, 0xda, 0x5c, 0xec, 0x50, 0x57, 0x08,
-	0xf1, 0xeb, 0xa1, 0xfa, 0x44, 0x8a, 0x57, 0x0f, 0xc5, 0x81, 0x0c, 0x42, 0xba, 0x5c, 0xec, 0x50,
-	0xab, 0x85, 0xf8, 0xf5, 0x50, 0x1d, 0x2b, 0xc5, 0xab, 0x87, 0xec, 0x2a, 0x25, 0x06, 0x03, 0x46,
-	0x27, 0x96, 0x28, 0xa6, 0x82, 0xa4, 0x24, 0x36, 0x70, 0xc4, 0x18, 0x03, 0x02, 0x00, 0x00, 0xff,
-	0xff, 0xa7, 0x8b, 0x3a, 0x2b, 0xa5, 0x01, 0x00, 0x00,
+	// 432 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x41, 0x8b, 0xd4, 0x30,
+	0x14, 0x9e, 0x4e, 0x3b, 0x33, 0xce, 0xdb, 0xb6, 0x23, 0x8f, 0x65, 0x29, 0x3d, 0x2d, 0x39, 0xe8,
+	0x82, 0x18, 0x64, 0x45, 0x04, 0x8f, 0x8a, 0xb0, 0x97, 0x05, 0xa9, 0xe0, 0xc1, 0x5b, 0x76, 0x1b,
+	0x6b, 0x9c, 0x36, 0xad, 0x4d, 0x46, 0x99, 0x5f, 0xeb, 0x5f, 0x91, 0xa4, 0x69, 0x6c, 0x2f, 0xc5,
+	0xdb, 0x7b, 0x4d, 0xf2, 0xbd, 0xef, 0x7d, 0xdf, 0x57, 0xd8, 0x37, 0xa7, 0x92, 0x76, 0x7d, 0xab,
+	0x5b, 0xf2, 0x19, 0x2e, 0x3e, 0x09, 0x59, 0x15, 0xfc, 0xe7, 0x89, 0x2b, 0x8d, 0x08, 0x91, 0x64,
+	0x0d, 0xcf, 0x82, 0xeb, 0xe0, 0x66, 0x5f, 0xd8, 0x1a, 0x2f, 0x61, 0xa3, 0xdb, 0x23, 0x97, 0xd9,
+	0xda, 0x7e, 0x1c, 0x1a, 0xcc, 0xe1, 0x49, 0xc7, 0x94, 0xfa, 0xdd, 0xf6, 0x65, 0x16, 0xda, 0x03,
+	0xdf, 0x93, 0x37, 0xb0, 0x1f, 0x40, 0xbb, 0xfa, 0xfc, 0xff, 0x90, 0xe4, 0x07, 0xa4, 0xf7, 0x5c,
+	0x29, 0x56, 0xf1, 0x91, 0x8e, 0xbf, 0x17, 0x4c, 0x47, 0x3f, 0x85, 0xb0, 0x51, 0x95, 0x7b, 0x6b,
+	0x4a, 0xcc, 0x60, 0xf7, 0xf8, 0x9d, 0x49, 0xc9, 0x6b, 0xc7, 0x65, 0x6c, 0xf1, 0x0a, 0xb6, 0x9a,
+	0xf5, 0x15, 0xd7, 0x59, 0x64, 0x0f, 0x5c, 0x47, 0x52, 0x88, 0xfd, 0xac, 0xae, 0x3e, 0x93, 0x67,
+	0x90, 0x16, 0xfc, 0x91, 0x8b, 0x5f, 0xcb, 0xb3, 0xc9, 0x1d, 0xc4, 0xfe, 0x9e, 0xd9, 0xce, 0x71,
+	0x09, 0xfe, 0x71, 0x41, 0x88, 0x8e, 0x42, 0x96, 0x8e, 0x9e, 0xad, 0xcd, 0xb7, 0x92, 0x69, 0x66,
+	0xc9, 0xc5, 0x85, 0xad, 0xc9, 0x3b, 0x48, 0x3f, 0xb4, 0x4d, 0xc3, 0x64, 0xb9, 0xbc, 0x2d, 0x42,
+	0x54, 0x0b, 0xc9, 0x47, 0x3c, 0x53, 0x1b, 0xf6, 0xfe, 0xad, 0x61, 0xff, 0x05, 0xd2, 0x3b, 0xa1,
+	0x74, 0xdb, 0x9f, 0x97, 0xb1, 0x26, 0x3a, 0xad, 0xe7, 0x3a, 0x5d, 0xc2, 0xa6, 0x16, 0x8d, 0xd0,
+	0x96, 0xe2, 0xa6, 0x18, 0x1a, 0xf2, 0x0d, 0x62, 0x87, 0xfb, 0x51, 0xea, 0xde, 0x7a, 0xa9, 0x85,
+	0xf3, 0x32, 0x2c, 0x6c, 0xbd, 0x80, 0x79, 0x05, 0x5b, 0xc5, 0x65, 0xc9, 0x7b, 0x67, 0x8a, 0xeb,
+	0x46, 0xcd, 0x22, 0xaf, 0x19, 0x79, 0xeb, 0xe7, 0x0c, 0xaa, 0x3e, 0x87, 0x1d, 0x97, 0xba, 0x17,
+	0x5c, 0x65, 0xc1, 0x75, 0x78, 0x73, 0x71, 0x9b, 0xd0, 0x29, 0x8f, 0x62, 0x3c, 0xbd, 0xfd, 0x13,
+	0x40, 0x78, 0x7f, 0x2a, 0x91, 0x40, 0x64, 0x12, 0x87, 0x31, 0x9d, 0xa4, 0x39, 0x07, 0xea, 0x63,
+	0x48, 0x56, 0xf8, 0x02, 0x76, 0xce, 0x72, 0x3c, 0xd0, 0x79, 0xd0, 0xf2, 0x84, 0xce, 0xd2, 0xb0,
+	0xc2, 0x97, 0xb0, 0x73, 0x3e, 0xe3, 0x81, 0xce, 0x93, 0x91, 0x27, 0x74, 0x1a, 0x01, 0xb2, 0x7a,
+	0x15, 0x18, 0x6c, 0x67, 0x08, 0x1e, 0xe8, 0xdc, 0xd6, 0x3c, 0xa1, 0x33, 0xaf, 0x2c, 0x11, 0xb7,
+	0x0d, 0x1e, 0xe8, 0xdc, 0xb7, 0x3c, 0xa1, 0x53, 0x21, 0xc8, 0xea, 0x7d, 0xf4, 0x75, 0xdd, 0x3d,
+	0x3c, 0x6c, 0xed, 0xdf, 0xfa, 0xfa, 0xef, 0x00, 0x71, 0xaf, 0x6b, 0x8e, 0xba, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -328,6 +631,10 @@ type MudClient interface {
 	Message(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*MessageReply, error)
 	// Receive Stream
 	Receive(ctx context.Context, in *ReceiveRequest, opts ...grpc.CallOption) (Mud_ReceiveClient, error)
+	// Execute a game command
+	Command(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandReply, error)
+	// Return recent messages of a channel, or of the room and every channel of the player
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryReply, error)
 }
 
 type mudClient struct {
@@ -388,6 +695,24 @@ func (x *mudReceiveClient) Recv() (*ReceiveReply, error) {
 	return m, nil
 }
 
+func (c *mudClient) Command(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandReply, error) {
+	out := new(CommandReply)
+	err := c.cc.Invoke(ctx, "/Mud/Command", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mudClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryReply, error) {
+	out := new(HistoryReply)
+	err := c.cc.Invoke(ctx, "/Mud/History", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MudServer is the server API for Mud service.
 type MudServer interface {
 	// Send a ping
@@ -396,6 +721,10 @@ type MudServer interface {
 	Message(context.Context, *MessageRequest) (*MessageReply, error)
 	// Receive Stream
 	Receive(*ReceiveRequest, Mud_ReceiveServer) error
+	// Execute a game command
+	Command(context.Context, *CommandRequest) (*CommandReply, error)
+	// Return recent messages of a channel, or of the room and every channel of the player
+	History(context.Context, *HistoryRequest) (*HistoryReply, error)
 }
 
 // UnimplementedMudServer can be embedded to have forward compatible implementations.
@@ -411,6 +740,12 @@ func (*UnimplementedMudServer) Message(ctx context.Context, req *MessageRequest)
 func (*UnimplementedMudServer) Receive(req *ReceiveRequest, srv Mud_ReceiveServer) error {
 	return status.Errorf(codes.Unimplemented, "method Receive not implemented")
 }
+func (*UnimplementedMudServer) Command(ctx context.Context, req *CommandRequest) (*CommandReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Command not implemented")
+}
+func (*UnimplementedMudServer) History(ctx context.Context, req *HistoryRequest) (*HistoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
 
 func RegisterMudServer(s *grpc.Server, srv MudServer) {
 	s.RegisterService(&_Mud_serviceDesc, srv)
@@ -473,6 +808,42 @@ func (x *mudReceiveServer) Send(m *ReceiveReply) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Mud_Command_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MudServer).Command(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Mud/Command",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MudServer).Command(ctx, req.(*CommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mud_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MudServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Mud/History",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MudServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Mud_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "Mud",
 	HandlerType: (*MudServer)(nil),
@@ -485,6 +856,14 @@ var _Mud_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Message",
 			Handler:    _Mud_Message_Handler,
 		},
+		{
+			MethodName: "Command",
+			Handler:    _Mud_Command_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _Mud_History_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
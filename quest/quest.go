@@ -0,0 +1,146 @@
+package quest
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/zrma/mud/world"
+)
+
+var (
+	ErrActive       = errors.New("quest already active")
+	ErrDone         = errors.New("quest already completed")
+	ErrLevel        = errors.New("level too low")
+	ErrPrerequisite = errors.New("required quest not completed")
+)
+
+// Event is something a player did that quests may be waiting for. Kind is one of the step kinds
+// of the world package and Target the template or room it happened to.
+type Event struct {
+	Kind   string
+	Target string
+	// Have is how many items of Target the player holds after a fetch event. Fetch steps count
+	// those rather than the events, so that picking the same item up again counts only once.
+	Have int
+}
+
+// Log is the quest state of one player. It is saved with the character.
+type Log struct {
+	Active map[string]*Progress `json:"active,omitempty"`
+	Done   map[string]bool      `json:"done,omitempty"`
+}
+
+// Progress is the current step of an active quest and how far into the step the player is.
+type Progress struct {
+	Step  int `json:"step"`
+	Count int `json:"count"`
+}
+
+func NewLog() *Log {
+	return &Log{
+		Active: make(map[string]*Progress),
+		Done:   make(map[string]bool),
+	}
+}
+
+// CanStart tells why a player of the given level may not take q, or nil.
+func (l *Log) CanStart(q *world.Quest, level int) error {
+	if _, ok := l.Active[q.ID]; ok {
+		return ErrActive
+	}
+	if l.Done[q.ID] {
+		return ErrDone
+	}
+	if level < q.Level {
+		return ErrLevel
+	}
+	for _, id := range q.Requires {
+		if !l.Done[id] {
+			return ErrPrerequisite
+		}
+	}
+	return nil
+}
+
+func (l *Log) Start(q *world.Quest, level int) error {
+	if err := l.CanStart(q, level); err != nil {
+		return err
+	}
+	if l.Active == nil {
+		l.Active = make(map[string]*Progress)
+	}
+	l.Active[q.ID] = &Progress{}
+	return nil
+}
+
+func (l *Log) Abandon(id string) bool {
+	if _, ok := l.Active[id]; !ok {
+		return false
+	}
+	delete(l.Active, id)
+	return true
+}
+
+// Update tells what an event did to an active quest. Step is the step that advanced, Completed
+// is set when it was the last one.
+type Update struct {
+	Quest     *world.Quest
+	Step      *world.Step
+	Count     int
+	StepDone  bool
+	Completed bool
+}
+
+// Apply advances every active quest whose current step waits for e. Completed quests move to
+// Done. Quests that no longer exist in quests are left alone, they may come back with a reload.
+func (l *Log) Apply(quests map[string]*world.Quest, e Event) []Update {
+	var updates []Update
+	for _, id := range l.ActiveIDs() {
+		q, ok := quests[id]
+		if !ok {
+			continue
+		}
+		p := l.Active[id]
+		if p.Step >= len(q.Steps) {
+			continue
+		}
+		step := q.Steps[p.Step]
+		if step.Kind != e.Kind || step.Target != e.Target {
+			continue
+		}
+
+		if step.Kind == world.StepFetch {
+			if e.Have <= p.Count {
+				continue
+			}
+			p.Count = e.Have
+		} else {
+			p.Count++
+		}
+		u := Update{Quest: q, Step: step, Count: p.Count}
+		if p.Count >= step.Needed() {
+			u.StepDone = true
+			p.Step++
+			p.Count = 0
+		}
+		if p.Step >= len(q.Steps) {
+			u.Completed = true
+			delete(l.Active, id)
+			if l.Done == nil {
+				l.Done = make(map[string]bool)
+			}
+			l.Done[id] = true
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
+
+func (l *Log) ActiveIDs() []string {
+	ids := make([]string, 0, len(l.Active))
+	for id := range l.Active {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,77 @@
+package role
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is what an account may do. Every role may do what the roles below it may.
+type Role int
+
+const (
+	Player Role = iota
+	// Builder edits the world.
+	Builder
+	// Moderator keeps order among the players.
+	Moderator
+	// Admin runs the server.
+	Admin
+)
+
+var All = []Role{Player, Builder, Moderator, Admin}
+
+var names = map[Role]string{
+	Player:    "player",
+	Builder:   "builder",
+	Moderator: "moderator",
+	Admin:     "admin",
+}
+
+var titles = map[Role]string{
+	Player:    "플레이어",
+	Builder:   "빌더",
+	Moderator: "운영자",
+	Admin:     "관리자",
+}
+
+func (r Role) String() string {
+	if name, ok := names[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("role(%d)", int(r))
+}
+
+// Title is the Korean name of the role, for players to read.
+func (r Role) Title() string {
+	if title, ok := titles[r]; ok {
+		return title
+	}
+	return r.String()
+}
+
+// Parse reads a role by its name or its Korean title, like "builder" or "빌더".
+func Parse(s string) (Role, error) {
+	for _, r := range All {
+		if strings.EqualFold(s, names[r]) || s == titles[r] {
+			return r, nil
+		}
+	}
+	return Player, fmt.Errorf("unknown role %q", s)
+}
+
+// MarshalText saves a role by its name, so that saved records and tokens stay readable.
+func (r Role) MarshalText() ([]byte, error) {
+	if _, ok := names[r]; !ok {
+		return nil, fmt.Errorf("unknown role %d", int(r))
+	}
+	return []byte(r.String()), nil
+}
+
+func (r *Role) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
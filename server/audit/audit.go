@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is one action an admin took.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Admin  string    `json:"admin"`
+	Action string    `json:"action"`
+	Target string    `json:"target,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Log appends entries as JSON lines, one entry per line, so that it can be read with the usual
+// line tools. It is safe for concurrent use.
+type Log struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+func New(w io.Writer) *Log {
+	return &Log{w: w}
+}
+
+// Open appends to the file at path, creating it and its directory when they don't exist.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return New(f), nil
+}
+
+func (l *Log) Write(e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, err = l.w.Write(append(b, '\n'))
+	return err
+}
+
+// Close closes the file of the log, when it writes to one.
+func (l *Log) Close() error {
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the game loop. Tests use Fake to move time by hand.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Fake only moves when Advance is called. Like time.Ticker, its tickers drop ticks nobody
+// received in time.
+type Fake struct {
+	sync.Mutex
+
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func (f *Fake) Now() time.Time {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	f.Lock()
+	defer f.Unlock()
+
+	t := &fakeTicker{f: f, c: make(chan time.Time, 1), d: d, next: f.now.Add(d)}
+	f.tickers = append(f.tickers, t)
+	return t
+}
+
+func (f *Fake) Advance(d time.Duration) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.now = f.now.Add(d)
+	for _, t := range f.tickers {
+		for !t.stopped && !t.next.After(f.now) {
+			select {
+			case t.c <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.d)
+		}
+	}
+}
+
+type fakeTicker struct {
+	f       *Fake
+	c       chan time.Time
+	d       time.Duration
+	next    time.Time
+	stopped bool
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.f.Lock()
+	defer t.f.Unlock()
+
+	t.stopped = true
+}
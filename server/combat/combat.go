@@ -0,0 +1,216 @@
+package combat
+
+import (
+	"math/rand"
+
+	"github.com/zrma/mud/character"
+)
+
+const (
+	baseHitChance   = 70
+	minHitChance    = 10
+	maxHitChance    = 95
+	defendPenalty   = 20
+	baseFleeChance  = 50
+	minFleeChance   = 10
+	maxFleeChance   = 90
+	staminaPerRound = 1
+)
+
+// Fighter is anything that can take part in a fight, players and mobs alike.
+type Fighter interface {
+	Character() *character.Character
+	Damage() int
+	Armor() int
+}
+
+// Hit is the outcome of a single attack in a round.
+type Hit struct {
+	Attacker Fighter
+	Target   Fighter
+	Missed   bool
+	Damage   int
+	Killed   bool
+}
+
+// Combat keeps who fights whom and resolves rounds. All randomness comes from the given source,
+// so a fight replays exactly under the same seed.
+type Combat struct {
+	rng *rand.Rand
+
+	fighters  []Fighter
+	targets   map[Fighter]Fighter
+	defending map[Fighter]bool
+}
+
+func New(rng *rand.Rand) *Combat {
+	return &Combat{
+		rng:       rng,
+		targets:   make(map[Fighter]Fighter),
+		defending: make(map[Fighter]bool),
+	}
+}
+
+// Engage makes attacker fight target. A target that isn't fighting yet fights back.
+func (c *Combat) Engage(attacker, target Fighter) {
+	c.join(attacker)
+	c.targets[attacker] = target
+	if _, ok := c.targets[target]; !ok {
+		c.join(target)
+		c.targets[target] = attacker
+	}
+}
+
+func (c *Combat) join(f Fighter) {
+	for _, other := range c.fighters {
+		if other == f {
+			return
+		}
+	}
+	c.fighters = append(c.fighters, f)
+}
+
+// Disengage takes f out of the fight. Whoever was attacking f looks for another opponent among
+// those attacking them, or stops.
+func (c *Combat) Disengage(f Fighter) {
+	delete(c.targets, f)
+	delete(c.defending, f)
+	for i, other := range c.fighters {
+		if other == f {
+			c.fighters = append(c.fighters[:i:i], c.fighters[i+1:]...)
+			break
+		}
+	}
+
+	for _, other := range c.fighters {
+		if c.targets[other] != f {
+			continue
+		}
+		delete(c.targets, other)
+		for _, attacker := range c.fighters {
+			if c.targets[attacker] == other {
+				c.targets[other] = attacker
+				break
+			}
+		}
+	}
+
+	for _, other := range c.fighters {
+		if _, ok := c.targets[other]; !ok {
+			c.Disengage(other)
+			return
+		}
+	}
+}
+
+func (c *Combat) Fighting(f Fighter) bool {
+	_, ok := c.targets[f]
+	return ok
+}
+
+func (c *Combat) Target(f Fighter) (Fighter, bool) {
+	t, ok := c.targets[f]
+	return t, ok
+}
+
+// Opponents lists everyone attacking f.
+func (c *Combat) Opponents(f Fighter) []Fighter {
+	var result []Fighter
+	for _, other := range c.fighters {
+		if c.targets[other] == f {
+			result = append(result, other)
+		}
+	}
+	return result
+}
+
+// Defend makes f dodge more and take less damage until its next round.
+func (c *Combat) Defend(f Fighter) {
+	c.defending[f] = true
+}
+
+// Flee rolls whether f escapes its opponents, and takes it out of the fight if it does.
+func (c *Combat) Flee(f Fighter) bool {
+	chance := baseFleeChance
+	for _, o := range c.Opponents(f) {
+		chance += (f.Character().Dexterity - o.Character().Dexterity) * 3
+		chance -= 10
+	}
+	chance = clamp(chance, minFleeChance, maxFleeChance)
+	if c.rng.Intn(100) >= chance {
+		return false
+	}
+	c.Disengage(f)
+	return true
+}
+
+// Round lets every fighter attack its target once, in the order they joined the fight.
+func (c *Combat) Round() []Hit {
+	var hits []Hit
+	for _, attacker := range append([]Fighter(nil), c.fighters...) {
+		target, ok := c.targets[attacker]
+		if !ok || attacker.Character().Dead() || target.Character().Dead() {
+			continue
+		}
+		defending := c.defending[target]
+
+		hit := Hit{Attacker: attacker, Target: target}
+		if !c.hits(attacker, target, defending) {
+			hit.Missed = true
+		} else {
+			hit.Damage = c.damage(attacker, target, defending)
+			tc := target.Character()
+			tc.HP -= hit.Damage
+			if tc.Dead() {
+				tc.HP = 0
+				hit.Killed = true
+			}
+		}
+		if ac := attacker.Character(); ac.Stamina > 0 {
+			ac.Stamina -= staminaPerRound
+		}
+		hits = append(hits, hit)
+
+		if hit.Killed {
+			c.Disengage(target)
+		}
+	}
+	for f := range c.defending {
+		delete(c.defending, f)
+	}
+	return hits
+}
+
+func (c *Combat) hits(attacker, target Fighter, defending bool) bool {
+	chance := baseHitChance + (attacker.Character().Dexterity-target.Character().Dexterity)*2
+	chance += (attacker.Character().Level - target.Character().Level) * 2
+	if defending {
+		chance -= defendPenalty
+	}
+	return c.rng.Intn(100) < clamp(chance, minHitChance, maxHitChance)
+}
+
+func (c *Combat) damage(attacker, target Fighter, defending bool) int {
+	damage := 1 + attacker.Character().Strength/4 + c.rng.Intn(attacker.Damage()+attacker.Character().Level+1)
+	damage -= target.Armor() / 2
+	if defending {
+		damage /= 2
+	}
+	if attacker.Character().Stamina <= 0 {
+		damage /= 2
+	}
+	if damage < 1 {
+		damage = 1
+	}
+	return damage
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
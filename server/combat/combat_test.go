@@ -0,0 +1,217 @@
+package combat_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/zrma/mud/character"
+	"github.com/zrma/mud/server/combat"
+)
+
+type fighter struct {
+	c      *character.Character
+	damage int
+	armor  int
+}
+
+func (f *fighter) Character() *character.Character { return f.c }
+func (f *fighter) Damage() int                     { return f.damage }
+func (f *fighter) Armor() int                      { return f.armor }
+
+func newFighter(name string, strength, dexterity int) *fighter {
+	c := character.New(name)
+	c.Strength, c.Dexterity = strength, dexterity
+	return &fighter{c: c}
+}
+
+// attack rolls one attack the way the rules say, on a source seeded like the one of the fight,
+// to tell what the attack should come to.
+func attack(r *rand.Rand, a, t *fighter, defending bool) (missed bool, damage int) {
+	chance := 70 + (a.c.Dexterity-t.c.Dexterity)*2 + (a.c.Level-t.c.Level)*2
+	if defending {
+		chance -= 20
+	}
+	if chance < 10 {
+		chance = 10
+	}
+	if chance > 95 {
+		chance = 95
+	}
+	if r.Intn(100) >= chance {
+		return true, 0
+	}
+
+	damage = 1 + a.c.Strength/4 + r.Intn(a.damage+a.c.Level+1) - t.armor/2
+	if defending {
+		damage /= 2
+	}
+	if a.c.Stamina <= 0 {
+		damage /= 2
+	}
+	if damage < 1 {
+		damage = 1
+	}
+	return false, damage
+}
+
+func TestRound(t *testing.T) {
+	var hit, missed bool
+	for seed := int64(1); seed <= 50; seed++ {
+		a, b := newFighter("철수", 12, 10), newFighter("늑대", 10, 10)
+		a.damage, b.armor = 4, 2
+		hp := b.c.HP
+
+		c := combat.New(rand.New(rand.NewSource(seed)))
+		c.Engage(a, b)
+		hits := c.Round()
+
+		r := rand.New(rand.NewSource(seed))
+		wantMissed, wantDamage := attack(r, a, b, false)
+		if len(hits) != 2 {
+			t.Fatalf("seed %d: %d hits, want one of each fighter", seed, len(hits))
+		}
+		got := hits[0]
+		if got.Attacker != a || got.Target != b {
+			t.Fatalf("seed %d: first hit %v, want 철수 attacking first", seed, got)
+		}
+		if got.Missed != wantMissed || got.Damage != wantDamage || got.Killed {
+			t.Errorf("seed %d: hit %+v, want missed %v and damage %d", seed, got, wantMissed, wantDamage)
+		}
+		if b.c.HP != hp-wantDamage {
+			t.Errorf("seed %d: hp %d, want %d", seed, b.c.HP, hp-wantDamage)
+		}
+		hit = hit || !wantMissed
+		missed = missed || wantMissed
+	}
+	if !hit || !missed {
+		t.Errorf("the seeds didn't cover both a hit and a miss")
+	}
+}
+
+func TestEquipmentAndStamina(t *testing.T) {
+	const seed = 3
+	a, b := newFighter("철수", 20, 50), newFighter("늑대", 10, 10)
+	a.damage, b.armor = 10, 6
+	a.c.Stamina = 0
+
+	c := combat.New(rand.New(rand.NewSource(seed)))
+	c.Engage(a, b)
+	got := c.Round()[0]
+
+	missed, damage := attack(rand.New(rand.NewSource(seed)), a, b, false)
+	if missed {
+		t.Fatal("the seed misses, pick one that hits")
+	}
+	if got.Missed != missed || got.Damage != damage {
+		t.Errorf("hit %+v, want missed %v and damage %d", got, missed, damage)
+	}
+}
+
+func TestKill(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		a, b := newFighter("철수", 10, 50), newFighter("늑대", 10, 10)
+		b.c.HP = 1
+
+		c := combat.New(rand.New(rand.NewSource(seed)))
+		c.Engage(a, b)
+		hits := c.Round()
+
+		if missed, _ := attack(rand.New(rand.NewSource(seed)), a, b, false); missed {
+			continue
+		}
+		if len(hits) != 1 || !hits[0].Killed {
+			t.Fatalf("seed %d: hits %+v, want 철수 killing 늑대 before it attacks", seed, hits)
+		}
+		if b.c.HP != 0 {
+			t.Errorf("seed %d: hp %d after dying, want 0", seed, b.c.HP)
+		}
+		if c.Fighting(a) || c.Fighting(b) {
+			t.Errorf("seed %d: still fighting after the only opponent died", seed)
+		}
+		return
+	}
+	t.Fatal("no seed hit")
+}
+
+func TestDefend(t *testing.T) {
+	var defendedHit bool
+	for seed := int64(1); seed <= 50; seed++ {
+		a, b := newFighter("철수", 30, 10), newFighter("늑대", 10, 10)
+		a.damage = 8
+
+		c := combat.New(rand.New(rand.NewSource(seed)))
+		c.Engage(a, b)
+		c.Defend(b)
+		first := c.Round()[0]
+		second := c.Round()[0]
+
+		r := rand.New(rand.NewSource(seed))
+		missed, damage := attack(r, a, b, true)
+		if first.Missed != missed || first.Damage != damage {
+			t.Errorf("seed %d: defended hit %+v, want missed %v and damage %d", seed, first, missed, damage)
+		}
+		// the other half of the round, 늑대 attacking
+		attack(r, b, a, false)
+		// defending lasts until the next round only
+		missed, damage = attack(r, a, b, false)
+		if second.Missed != missed || second.Damage != damage {
+			t.Errorf("seed %d: next hit %+v, want missed %v and damage %d", seed, second, missed, damage)
+		}
+		defendedHit = defendedHit || first.Damage > 0
+	}
+	if !defendedHit {
+		t.Error("no seed hit a defending fighter")
+	}
+}
+
+func TestFlee(t *testing.T) {
+	var fled, caught bool
+	for seed := int64(1); seed <= 20; seed++ {
+		a, b := newFighter("철수", 10, 14), newFighter("늑대", 10, 10)
+
+		c := combat.New(rand.New(rand.NewSource(seed)))
+		c.Engage(b, a)
+		got := c.Flee(a)
+
+		// 50, 3 for every point of dexterity ahead, 10 less for the one opponent
+		want := rand.New(rand.NewSource(seed)).Intn(100) < 50+4*3-10
+		if got != want {
+			t.Errorf("seed %d: fled %v, want %v", seed, got, want)
+		}
+		if got && (c.Fighting(a) || c.Fighting(b)) {
+			t.Errorf("seed %d: still fighting after fleeing", seed)
+		}
+		if !got && !c.Fighting(a) {
+			t.Errorf("seed %d: out of the fight after failing to flee", seed)
+		}
+		fled, caught = fled || got, caught || !got
+	}
+	if !fled || !caught {
+		t.Error("the seeds didn't cover both fleeing and failing to")
+	}
+}
+
+// TestReplay fights to the end twice under the same seed.
+func TestReplay(t *testing.T) {
+	fight := func() []combat.Hit {
+		a, b := newFighter("철수", 12, 11), newFighter("늑대", 11, 10)
+		a.damage, b.armor = 3, 1
+		c := combat.New(rand.New(rand.NewSource(42)))
+		c.Engage(a, b)
+
+		var all []combat.Hit
+		for i := 0; i < 100 && c.Fighting(a); i++ {
+			for _, h := range c.Round() {
+				// the fighters differ between fights, only the outcomes are compared
+				h.Attacker, h.Target = nil, nil
+				all = append(all, h)
+			}
+		}
+		return all
+	}
+	first, second := fight(), fight()
+	if len(first) == 0 || !reflect.DeepEqual(first, second) {
+		t.Errorf("fights under the same seed differ:\n%+v\n%+v", first, second)
+	}
+}
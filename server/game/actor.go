@@ -0,0 +1,43 @@
+package game
+
+import (
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/server/combat"
+)
+
+// Actor is anyone who runs commands, a player behind a session or a mob moved by its behaviors.
+// Both go through the same handlers, so a mob saying or attacking something takes the same code
+// path as a player doing it.
+type Actor interface {
+	combat.Fighter
+	Name() string
+	Room() string
+	Inventory() *item.Inventory
+	Send(msg string)
+	SendEvent(kind event.Kind, v interface{}) error
+
+	setRoom(room string)
+}
+
+// subject is the name of an actor as the subject of a sentence, escaped to go into markup.
+// Players are addressed politely.
+func subject(a Actor) string {
+	name := markup.Escape(a.Name())
+	if _, ok := a.(*Player); ok {
+		return name + "님이"
+	}
+	return name + josa.Pick(a.Name(), "이/가")
+}
+
+// recipient is the name of an actor followed by the particle for "to", escaped to go into
+// markup.
+func recipient(a Actor) string {
+	name := markup.Escape(a.Name())
+	if _, ok := a.(*Player); ok {
+		return name + "님에게"
+	}
+	return name + "에게"
+}
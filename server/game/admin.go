@@ -0,0 +1,483 @@
+package game
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/role"
+	"github.com/zrma/mud/server/audit"
+	"github.com/zrma/mud/store"
+)
+
+var ErrBanned = errors.New("banned from the server")
+
+// defaultMute is how long 채금 mutes a player when no time is given.
+const defaultMute = 10 * time.Minute
+
+func (g *Game) banned(name, address string) bool {
+	if _, ok := g.bans.Accounts[name]; ok {
+		return true
+	}
+	_, ok := g.bans.Addresses[address]
+	return ok
+}
+
+// record writes an action taken with a role above player to the audit log.
+func (g *Game) record(admin Actor, action, target, detail string) {
+	g.logger.Info(
+		"admin action",
+		"admin", admin.Name(),
+		"action", action,
+		"target", target,
+		"detail", detail,
+	)
+	if g.audit == nil {
+		return
+	}
+	if err := g.audit.Write(audit.Entry{
+		Time:   g.clock.Now(),
+		Admin:  admin.Name(),
+		Action: action,
+		Target: target,
+		Detail: detail,
+	}); err != nil {
+		g.logger.Err(
+			"audit writing failed",
+			"action", action,
+			"err", err,
+		)
+	}
+}
+
+// kick saves the player and ends its session, after telling it why.
+func (g *Game) kick(p *Player, msg string) {
+	p.Send("{R}" + msg + "{x}")
+	for token, other := range g.players {
+		if other == p {
+			g.leave(token)
+		}
+	}
+	p.session.Close()
+}
+
+func (g *Game) teleport(p Actor, room string) {
+	g.combat.Disengage(p)
+	g.sendRoom(p.Room(), subject(p)+" 사라졌습니다.", p)
+	p.setRoom(room)
+	g.sendRoom(p.Room(), subject(p)+" 나타났습니다.", p)
+	g.look(p)
+	g.enter(p)
+}
+
+// target splits the arguments of an admin command into whom it is about, named with or without
+// a particle, and the rest.
+func target(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	a := parseArgs(args[:1])
+	if a.to != "" {
+		return a.to, args[1:]
+	}
+	return a.object, args[1:]
+}
+
+// outranks tells whether p may act on another player. Only admins may act on their equals.
+func outranks(p Actor, other *Player) bool {
+	return outranksRole(p, other.role)
+}
+
+func outranksRole(p Actor, other role.Role) bool {
+	r := roleOf(p)
+	return other < r || r == role.Admin
+}
+
+// accountRole tells the role of an account, also when it isn't online. The saved role counts even
+// while the account has no password, so that it can't be banned before it gets one.
+func (g *Game) accountRole(name string) (role.Role, error) {
+	r := role.Player
+	if other, ok := g.playerNamed(name); ok {
+		r = other.role
+	}
+	if g.store == nil {
+		return r, nil
+	}
+	a, err := g.store.LoadAccount(name)
+	switch err {
+	case nil:
+		if a.Role > r {
+			r = a.Role
+		}
+	case store.ErrNotFound:
+	default:
+		g.storeFailed("account", name, err)
+		return r, err
+	}
+	return r, nil
+}
+
+func because(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " 사유: " + markup.Escape(reason)
+}
+
+// 추방 disconnects a player: "철수 추방", with a reason after the name if there is one.
+var _ = registerFor(role.Moderator, "추방", func(g *Game, p Actor, args []string) error {
+	name, rest := target(args)
+	if name == "" {
+		p.Send("누구를 내보낼까요? 예: 철수 추방")
+		return nil
+	}
+	to, ok := g.playerNamed(name)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	if to == p {
+		p.Send("자신을 내보낼 수는 없습니다.")
+		return nil
+	}
+	if !outranks(p, to) {
+		return ErrPermission
+	}
+	reason := strings.Join(rest, " ")
+	g.kick(to, "관리자가 접속을 끊었습니다."+because(reason))
+	g.record(p, "kick", name, reason)
+	p.Send(markup.Escape(name) + "님을 내보냈습니다.")
+	return nil
+})
+
+// 차단 bans an account by name, or an address by IP, and disconnects who is using it. Only
+// accounts of a lower role can be banned, and addresses that none of a higher role is using.
+var _ = registerFor(role.Moderator, "차단", func(g *Game, p Actor, args []string) error {
+	name, rest := target(args)
+	if name == "" {
+		p.Send("누구를 차단할까요? 예: 철수 차단, 10.0.0.1 차단")
+		return nil
+	}
+	reason := strings.Join(rest, " ")
+	ban := &store.Ban{By: p.Name(), Reason: reason, Time: g.clock.Now()}
+	msg := "관리자가 접속을 막았습니다." + because(reason)
+
+	if net.ParseIP(name) != nil {
+		var using []*Player
+		for _, other := range g.sortedPlayers() {
+			if other.address != name || other == p {
+				continue
+			}
+			if !outranks(p, other) {
+				return ErrPermission
+			}
+			using = append(using, other)
+		}
+		g.bans.Addresses[name] = ban
+		for _, other := range using {
+			g.kick(other, msg)
+		}
+		p.Send(markup.Escape(name) + " 주소를 차단했습니다.")
+	} else {
+		if name == p.Name() {
+			p.Send("자신을 차단할 수는 없습니다.")
+			return nil
+		}
+		r, err := g.accountRole(name)
+		if err != nil {
+			return err
+		}
+		if !outranksRole(p, r) {
+			return ErrPermission
+		}
+		other, online := g.playerNamed(name)
+		g.bans.Accounts[name] = ban
+		if online {
+			g.kick(other, msg)
+		}
+		p.Send(markup.Escape(name) + " 계정을 차단했습니다.")
+	}
+	g.record(p, "ban", name, reason)
+	g.saveWorld()
+	return nil
+})
+
+var _ = registerFor(role.Moderator, "차단해제", func(g *Game, p Actor, args []string) error {
+	name, _ := target(args)
+	if name == "" {
+		p.Send("누구의 차단을 풀까요? 예: 철수 차단해제")
+		return nil
+	}
+	_, account := g.bans.Accounts[name]
+	_, address := g.bans.Addresses[name]
+	if !account && !address {
+		p.Send("차단된 계정이나 주소가 아닙니다: " + markup.Escape(name))
+		return nil
+	}
+	delete(g.bans.Accounts, name)
+	delete(g.bans.Addresses, name)
+	g.record(p, "unban", name, "")
+	g.saveWorld()
+	p.Send(markup.Escape(name) + "의 차단을 풀었습니다.")
+	return nil
+})
+
+var _ = registerFor(role.Moderator, "차단목록", func(g *Game, p Actor, args []string) error {
+	lines := []string{"{W}차단 목록{x}"}
+	list := func(kind string, bans map[string]*store.Ban) {
+		names := make([]string, 0, len(bans))
+		for name := range bans {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b := bans[name]
+			lines = append(lines, "  "+kind+" "+markup.Escape(name)+" - "+markup.Escape(b.By)+", "+
+				b.Time.Format("2006-01-02 15:04")+because(b.Reason))
+		}
+	}
+	list("계정", g.bans.Accounts)
+	list("주소", g.bans.Addresses)
+	if len(lines) == 1 {
+		lines = append(lines, "  없음")
+	}
+	p.Send(strings.Join(lines, "\n"))
+	return nil
+})
+
+// 채금 keeps a player from talking for some minutes: "철수 30 채금". Zero minutes lifts it.
+var _ = registerFor(role.Moderator, "채금", func(g *Game, p Actor, args []string) error {
+	name, rest := target(args)
+	if name == "" {
+		p.Send("누구의 말을 막을까요? 예: 철수 10 채금")
+		return nil
+	}
+	d := defaultMute
+	if len(rest) > 0 {
+		minutes, err := strconv.Atoi(rest[0])
+		if err != nil || minutes < 0 {
+			p.Send("몇 분 동안 막을지 숫자로 적어 주세요. 예: 철수 10 채금")
+			return nil
+		}
+		d = time.Duration(minutes) * time.Minute
+	}
+	to, ok := g.playerNamed(name)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	if !outranks(p, to) {
+		return ErrPermission
+	}
+
+	if d == 0 {
+		g.unmute(to)
+		to.Send("다시 말할 수 있습니다.")
+		g.record(p, "unmute", name, "")
+		p.Send(markup.Escape(name) + "님이 다시 말할 수 있습니다.")
+		return nil
+	}
+	g.mute(to, d)
+	to.Send("관리자가 " + duration(d) + " 동안 말을 막았습니다.")
+	g.record(p, "mute", name, d.String())
+	p.Send(markup.Escape(name) + "님의 말을 " + duration(d) + " 동안 막았습니다.")
+	return nil
+})
+
+// 순간이동 moves an admin or a player to a room or to another player: "town-inn에 순간이동",
+// "철수에게 순간이동", "철수를 영희에게 순간이동".
+var _ = registerFor(role.Builder, "순간이동", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	var who Actor = p
+	if a.object != "" {
+		other, ok := g.playerNamed(a.object)
+		if !ok {
+			return chatFailed(p, ErrNoPlayer)
+		}
+		// builders move only themselves
+		if other != p && roleOf(p) < role.Moderator {
+			return ErrPermission
+		}
+		who = other
+	}
+	room := a.into
+	if a.to != "" {
+		other, ok := g.playerNamed(a.to)
+		if !ok {
+			return chatFailed(p, ErrNoPlayer)
+		}
+		room = other.Room()
+	}
+	if room == "" {
+		p.Send("어디로 옮길까요? 예: town-inn에 순간이동, 철수에게 순간이동")
+		return nil
+	}
+	if _, ok := g.world.Room(room); !ok {
+		p.Send("그런 방은 없습니다: " + markup.Escape(room))
+		return nil
+	}
+
+	g.teleport(who, room)
+	g.record(p, "teleport", who.Name(), room)
+	if who != p {
+		p.Send(markup.Escape(who.Name()) + "님을 " + markup.Escape(room) + "에 옮겼습니다.")
+	}
+	return nil
+})
+
+// 강제 makes a player run a command: "철수에게 북 강제".
+var _ = registerFor(role.Admin, "강제", func(g *Game, p Actor, args []string) error {
+	var name string
+	if len(args) > 0 {
+		name = parseArgs(args[:1]).to
+	}
+	if name == "" || len(args) < 2 {
+		p.Send("누구에게 무엇을 시킬까요? 예: 철수에게 북 강제")
+		return nil
+	}
+	to, ok := g.playerNamed(name)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	line := strings.Join(args[1:], " ")
+	g.record(p, "force", name, line)
+	if err := g.execute(to, line); err != nil {
+		switch err {
+		case ErrUnknownCommand:
+			p.Send("그런 명령어는 없습니다: " + markup.Escape(line))
+			return nil
+		case ErrPermission:
+			p.Send(markup.Escape(name) + "님에게는 그 명령어를 쓸 권한이 없습니다.")
+			return nil
+		}
+		return err
+	}
+	p.Send(markup.Escape(name) + "님에게 시켰습니다: " + markup.Escape(line))
+	return nil
+})
+
+// 점검 shows what an admin needs to know about a player.
+var _ = registerFor(role.Moderator, "점검", func(g *Game, p Actor, args []string) error {
+	name, _ := target(args)
+	if name == "" {
+		p.Send("누구를 점검할까요? 예: 철수 점검")
+		return nil
+	}
+	to, ok := g.playerNamed(name)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	c := to.Character()
+
+	title := "{W}" + markup.Escape(to.Name()) + "{x} (" + to.role.Title() + ")"
+	where := markup.Escape(to.Room())
+	if room, ok := g.world.Room(to.Room()); ok {
+		where = markup.Escape(room.Name) + " (" + where + ")"
+	}
+	lines := []string{
+		title,
+		"  위치: " + where,
+		"  주소: " + markup.Escape(to.address),
+		"  레벨 " + strconv.Itoa(c.Level) + ", 체력 " + strconv.Itoa(c.HP) + "/" + strconv.Itoa(c.MaxHP) +
+			", 금화 " + strconv.Itoa(c.Gold),
+		"  소지품 " + strconv.Itoa(len(to.inventory.Items)) + "개, 착용 " +
+			strconv.Itoa(len(to.inventory.Equipment)) + "개",
+	}
+	if now := g.clock.Now(); now.Before(to.mutedUntil) {
+		lines = append(lines, "  채금: "+duration(to.mutedUntil.Sub(now))+" 남음")
+	}
+	if g.combat.Fighting(to) {
+		lines = append(lines, "  전투 중")
+	}
+	g.record(p, "inspect", name, "")
+	p.Send(strings.Join(lines, "\n"))
+	return nil
+})
+
+// 공지 tells every player something: "오늘 밤 점검이 있습니다 공지".
+var _ = registerFor(role.Moderator, "공지", func(g *Game, p Actor, args []string) error {
+	if len(args) == 0 {
+		p.Send("무엇을 알릴까요? 예: 오늘 밤 점검이 있습니다 공지")
+		return nil
+	}
+	msg := strings.Join(args, " ")
+	for _, other := range g.sortedPlayers() {
+		other.Send("{R}[공지]{x} " + markup.Escape(msg))
+	}
+	g.record(p, "broadcast", "", msg)
+	return nil
+})
+
+// 권한 gives an account a role: "철수 빌더 권한", or "철수 builder 권한". Without a role it shows
+// the role of the player. Roles above player need an account with a password.
+var _ = registerFor(role.Admin, "권한", func(g *Game, p Actor, args []string) error {
+	name, rest := target(args)
+	if name == "" {
+		p.Send("누구의 권한을 바꿀까요? 예: 철수 빌더 권한")
+		return nil
+	}
+	to, online := g.playerNamed(name)
+	if len(rest) == 0 {
+		if !online {
+			return chatFailed(p, ErrNoPlayer)
+		}
+		p.Send(markup.Escape(name) + "님의 권한: " + to.role.Title())
+		return nil
+	}
+	r, err := role.Parse(rest[0])
+	if err != nil {
+		p.Send("그런 권한은 없습니다: " + markup.Escape(rest[0]))
+		return nil
+	}
+	if name == p.Name() {
+		p.Send("자신의 권한은 바꿀 수 없습니다.")
+		return nil
+	}
+
+	if g.store != nil {
+		a, err := g.store.LoadAccount(name)
+		switch {
+		case err == store.ErrNotFound && !online:
+			return chatFailed(p, ErrNoPlayer)
+		case err == store.ErrNotFound:
+			a = &store.Account{Name: name, Created: g.clock.Now()}
+		case err != nil:
+			g.storeFailed("account", name, err)
+			return err
+		}
+		// a role above player only counts with a password, see login
+		if r != role.Player && a.Password == "" {
+			p.Send(markup.Escape(name) + "님의 계정에는 비밀번호가 없어 플레이어보다 높은 권한을 줄 수 없습니다.")
+			return nil
+		}
+		a.Role = r
+		if err := g.store.SaveAccount(a); err != nil {
+			g.storeFailed("account", name, err)
+			return err
+		}
+	} else if !online {
+		return chatFailed(p, ErrNoPlayer)
+	} else if r != role.Player {
+		p.Send("계정을 저장하지 않는 서버에서는 플레이어보다 높은 권한을 줄 수 없습니다.")
+		return nil
+	}
+
+	if online {
+		to.role = r
+		to.Send("권한이 " + josa.Attach(r.Title(), "으로/로") + " 바뀌었습니다.")
+		if err := to.SendEvent(event.CommandsKind, event.Commands{Words: wordsFor(to)}); err != nil {
+			g.logger.Warn(
+				"event sending failed",
+				"kind", event.CommandsKind,
+				"err", err,
+			)
+		}
+	}
+	g.record(p, "role", name, r.String())
+	p.Send(markup.Escape(name) + "님의 권한을 " + josa.Attach(r.Title(), "으로/로") + " 바꿨습니다.")
+	return nil
+})
@@ -0,0 +1,132 @@
+package game
+
+import (
+	"time"
+
+	"github.com/zrma/mud/server/npc"
+	"github.com/zrma/mud/server/script"
+	"github.com/zrma/mud/world"
+)
+
+const aiInterval = 3 * time.Second
+
+// mobWorld shows the game to the behaviors of one mob.
+type mobWorld struct {
+	g *Game
+	m *Mob
+}
+
+func (w mobWorld) Room() string {
+	return w.m.Room()
+}
+
+func (w mobWorld) Home() string {
+	return w.m.Home
+}
+
+func (w mobWorld) Exits() map[string]string {
+	exits := make(map[string]string)
+	room, ok := w.g.world.Room(w.m.Room())
+	if !ok {
+		return exits
+	}
+	for dir, to := range room.Exits {
+		if r, ok := w.g.world.Room(to); ok && r.Area == w.m.t.Area {
+			exits[dir] = to
+		}
+	}
+	return exits
+}
+
+func (w mobWorld) Players() []string {
+	var names []string
+	for _, p := range w.g.playersIn(w.m.Room()) {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+func (w mobWorld) Attackers() []string {
+	var names []string
+	for _, m := range w.g.mobsIn(w.m.Room()) {
+		if t, ok := w.g.combat.Target(m); ok {
+			if _, ok := t.(*Player); ok && m != w.m {
+				names = append(names, m.Name())
+			}
+		}
+	}
+	return names
+}
+
+func (w mobWorld) Fighting() bool {
+	return w.g.combat.Fighting(w.m)
+}
+
+func (w mobWorld) Execute(line string) {
+	if err := w.g.execute(w.m, line); err != nil {
+		w.g.logger.Warn(
+			"mob command failed",
+			"mob", w.m.Template,
+			"line", line,
+			"err", err,
+		)
+	}
+}
+
+func (w mobWorld) Intn(n int) int {
+	return w.g.rng.Intn(n)
+}
+
+// runAI gives every mob a turn. A mob killed during the turn of another is skipped.
+func (g *Game) runAI() {
+	g.eachMob(func(w npc.World, b npc.Behavior) {
+		b.Tick(w)
+	}, g.mobs)
+}
+
+// arrive lets the mobs of the room react to a player coming in.
+func (g *Game) arrive(p *Player) {
+	mobs := g.mobsIn(p.Room())
+	g.eachMob(func(w npc.World, b npc.Behavior) {
+		b.Arrive(w, p.Name())
+	}, mobs)
+	g.mobHooks(mobs, script.OnEnter, ref(p))
+}
+
+// hear lets the room and the mobs in it react to what a player said.
+func (g *Game) hear(p *Player, msg string) {
+	mobs := g.mobsIn(p.Room())
+	g.eachMob(func(w npc.World, b npc.Behavior) {
+		b.Hear(w, p.Name(), msg)
+	}, mobs)
+	talked := make(map[string]bool)
+	for _, m := range mobs {
+		if !talked[m.Template] {
+			talked[m.Template] = true
+			g.emit(p, world.StepTalk, m.Template)
+		}
+	}
+	g.mobHooks(mobs, script.OnSay, ref(p), msg)
+	g.roomHook(p.Room(), script.OnSay, ref(p), msg)
+}
+
+func (g *Game) eachMob(f func(w npc.World, b npc.Behavior), mobs []*Mob) {
+	mobs = append([]*Mob(nil), mobs...)
+	for _, m := range mobs {
+		for _, b := range m.behaviors {
+			if !g.alive(m) {
+				break
+			}
+			f(mobWorld{g: g, m: m}, b)
+		}
+	}
+}
+
+func (g *Game) alive(m *Mob) bool {
+	for _, other := range g.mobs {
+		if other == m {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package game
+
+import "strings"
+
+// Korean marks the role of a word with a particle instead of its position, so the arguments of
+// a command are told apart by their endings: "상자에서 빵을 주워", "철수에게 빵을 줘".
+
+const (
+	fromParticle = "에서"
+	toParticle   = "에게"
+	intoParticle = "에"
+)
+
+var objectParticles = []string{"을", "를"}
+
+type arguments struct {
+	object string
+	from   string
+	to     string
+	into   string
+}
+
+func parseArgs(args []string) arguments {
+	var a arguments
+	var object []string
+	for _, word := range args {
+		switch {
+		case strings.HasSuffix(word, fromParticle) && len(word) > len(fromParticle):
+			a.from = strings.TrimSuffix(word, fromParticle)
+		case strings.HasSuffix(word, toParticle) && len(word) > len(toParticle):
+			a.to = strings.TrimSuffix(word, toParticle)
+		case strings.HasSuffix(word, "한테") && len(word) > len("한테"):
+			a.to = strings.TrimSuffix(word, "한테")
+		case strings.HasSuffix(word, intoParticle) && len(word) > len(intoParticle):
+			a.into = strings.TrimSuffix(word, intoParticle)
+		default:
+			object = append(object, word)
+		}
+	}
+	a.object = stripObject(strings.Join(object, " "))
+	return a
+}
+
+func stripObject(word string) string {
+	for _, p := range objectParticles {
+		if strings.HasSuffix(word, p) && len(word) > len(p) {
+			return strings.TrimSuffix(word, p)
+		}
+	}
+	return word
+}
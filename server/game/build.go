@@ -0,0 +1,624 @@
+package game
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/role"
+	"github.com/zrma/mud/world"
+)
+
+// Builders change the world by rewriting its area files and reloading them, so that every edit
+// is validated like a change made by hand and survives a restart.
+
+const (
+	// undoDepth is how many edits of a builder 되돌리기 can take back.
+	undoDepth = 10
+	// noDescription is the description of new rooms and items until 설명편집 changes it.
+	noDescription = "아직 설명이 없습니다."
+)
+
+var (
+	ErrBusy      = errors.New("another edit is still being applied")
+	ErrNoEdit    = errors.New("nothing to undo")
+	ErrEdited    = errors.New("area file changed since the edit")
+	ErrNotInFile = errors.New("not in its area file")
+)
+
+// opposite is the way back through an exit, for linking rooms both ways.
+var opposite = map[string]string{
+	"북":  "남",
+	"남":  "북",
+	"동":  "서",
+	"서":  "동",
+	"위":  "아래",
+	"아래": "위",
+}
+
+// fileEdit is how an edit changed an area file.
+type fileEdit struct {
+	path   string
+	before []byte
+	after  []byte
+}
+
+func (f fileEdit) content(undo bool) []byte {
+	if undo {
+		return f.before
+	}
+	return f.after
+}
+
+// edit is a change of the world a builder can undo.
+type edit struct {
+	what  string
+	files []fileEdit
+}
+
+// build changes copies of areas, read from their files, writes them back and applies the world
+// they make. Nothing changes when that world isn't valid. Reading and validating the world takes
+// a while, so it happens without the game lock, like Reload, and done tells how it went once the
+// game lock is held again. One edit is applied at a time, ErrBusy refuses the others.
+func (g *Game) build(p *Player, what string, areas []string, f func(areas map[string]*world.Area) error, done func(err error)) {
+	if g.editing {
+		done(ErrBusy)
+		return
+	}
+	paths := make(map[string]string)
+	for _, id := range areas {
+		live, ok := g.world.Areas[id]
+		if !ok {
+			done(errors.New("unknown area " + id))
+			return
+		}
+		paths[id] = live.File()
+	}
+
+	g.editing = true
+	go func() {
+		g.building.Lock()
+		defer g.building.Unlock()
+
+		e, err := edited(what, paths, f)
+		var w *world.World
+		if err == nil {
+			w, err = g.rewrite(e.files, false)
+		}
+
+		g.Lock()
+		defer g.Unlock()
+
+		g.editing = false
+		if err == nil {
+			g.apply(w)
+			p.edits = append(p.edits, e)
+			if len(p.edits) > undoDepth {
+				p.edits = p.edits[len(p.edits)-undoDepth:]
+			}
+			g.record(p, "build", "", what)
+		}
+		done(err)
+	}()
+}
+
+// edited reads the area files, changes them with f and tells how the files change.
+func edited(what string, paths map[string]string, f func(areas map[string]*world.Area) error) (edit, error) {
+	e := edit{what: what}
+	copies := make(map[string]*world.Area)
+	for id, path := range paths {
+		a, err := world.LoadArea(path)
+		if err != nil {
+			return e, err
+		}
+		copies[id] = a
+	}
+	if err := f(copies); err != nil {
+		return e, err
+	}
+
+	ids := make([]string, 0, len(copies))
+	for id := range copies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		a := copies[id]
+		before, err := ioutil.ReadFile(a.File())
+		if err != nil {
+			return e, err
+		}
+		after, err := world.Marshal(a)
+		if err != nil {
+			return e, err
+		}
+		e.files = append(e.files, fileEdit{path: a.File(), before: before, after: after})
+	}
+	return e, nil
+}
+
+// undo takes back the last edit of a builder, unless its files were changed again since. Like
+// build it applies the world without the game lock and done tells how it went.
+func (g *Game) undo(p *Player, done func(what string, err error)) {
+	if g.editing {
+		done("", ErrBusy)
+		return
+	}
+	if len(p.edits) == 0 {
+		done("", ErrNoEdit)
+		return
+	}
+	e := p.edits[len(p.edits)-1]
+
+	g.editing = true
+	go func() {
+		g.building.Lock()
+		defer g.building.Unlock()
+
+		var w *world.World
+		err := unchanged(e.files)
+		if err == nil {
+			w, err = g.rewrite(e.files, true)
+		}
+
+		g.Lock()
+		defer g.Unlock()
+
+		g.editing = false
+		if err == nil {
+			g.apply(w)
+			p.edits = p.edits[:len(p.edits)-1]
+			g.record(p, "undo", "", e.what)
+		}
+		done(e.what, err)
+	}()
+}
+
+// unchanged tells ErrEdited when the files of an edit were changed again after it.
+func unchanged(files []fileEdit) error {
+	for _, f := range files {
+		current, err := ioutil.ReadFile(f.path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, f.after) {
+			return ErrEdited
+		}
+	}
+	return nil
+}
+
+// rewrite writes the files of an edit, or what they were before it when undoing, and loads the
+// world they make. When that world isn't valid the files are put back.
+func (g *Game) rewrite(files []fileEdit, undo bool) (*world.World, error) {
+	for i, f := range files {
+		if err := world.WriteFile(f.path, f.content(undo)); err != nil {
+			g.putBack(files[:i], undo)
+			return nil, err
+		}
+	}
+	w, err := g.load()
+	if err != nil {
+		g.putBack(files, undo)
+		return nil, err
+	}
+	return w, nil
+}
+
+func (g *Game) putBack(files []fileEdit, undo bool) {
+	for _, f := range files {
+		if err := world.WriteFile(f.path, f.content(!undo)); err != nil {
+			g.logger.Err(
+				"area file restoring failed",
+				"file", f.path,
+				"err", err,
+			)
+		}
+	}
+}
+
+// freeID makes up an id like town-room-3 that nothing in the world uses yet.
+func (g *Game) freeID(area, kind string) string {
+	for n := 1; ; n++ {
+		id := area + "-" + kind + "-" + strconv.Itoa(n)
+		_, room := g.world.Rooms[id]
+		_, tmpl := g.world.Items[id]
+		_, mob := g.world.Mobs[id]
+		_, quest := g.world.Quests[id]
+		if !room && !tmpl && !mob && !quest {
+			return id
+		}
+	}
+}
+
+func roomIn(a *world.Area, id string) (*world.Room, error) {
+	for _, r := range a.Rooms {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, ErrNotInFile
+}
+
+func itemIn(a *world.Area, id string) (*world.ItemTemplate, error) {
+	for _, t := range a.Items {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, ErrNotInFile
+}
+
+func setExit(r *world.Room, dir, to string) {
+	if r.Exits == nil {
+		r.Exits = make(map[string]string)
+	}
+	r.Exits[dir] = to
+}
+
+func buildFailed(p Actor, err error) error {
+	switch err {
+	case ErrBusy:
+		p.Send("다른 편집을 적용하고 있습니다. 잠시 후에 다시 해 보세요.")
+	case ErrNoEdit:
+		p.Send("되돌릴 편집이 없습니다.")
+	case ErrEdited:
+		p.Send("그 뒤로 영역 파일이 바뀌어 되돌릴 수 없습니다.")
+	default:
+		p.Send("{R}월드를 바꾸지 못했습니다.{x}")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			p.Send(markup.Escape(line))
+		}
+	}
+	return nil
+}
+
+// editor collects the lines of a description until the builder ends it with ".". Every line the
+// builder sends in the meantime goes to the editor instead of running as a command.
+type editor struct {
+	// room or item template being described
+	room  string
+	item  string
+	name  string
+	lines []string
+}
+
+const editorHelp = "한 줄씩 입력하세요. '.'만 적으면 저장하고, '.취소'는 취소, '.보기'는 지금까지 쓴 내용, " +
+	"'.지움'은 마지막 줄을 지웁니다."
+
+// setEditor opens the editor of a player, or closes it when e is nil, and tells the client.
+func (g *Game) setEditor(p *Player, e *editor) {
+	p.editor = e
+	g.sendEditor(p)
+}
+
+// sendEditor tells the client of a player whether the editor is open, so that it sends the lines
+// as typed while it is.
+func (g *Game) sendEditor(p *Player) {
+	if err := p.SendEvent(event.EditorKind, event.Editor{Open: p.editor != nil}); err != nil {
+		g.logger.Warn(
+			"event sending failed",
+			"kind", event.EditorKind,
+			"err", err,
+		)
+	}
+}
+
+// edit takes a line of the player's editor.
+func (g *Game) edit(p *Player, line string) {
+	e := p.editor
+	switch strings.TrimSpace(line) {
+	case ".":
+		g.setEditor(p, nil)
+		if len(e.lines) == 0 {
+			p.Send("설명이 비어 있어 바꾸지 않았습니다.")
+			return
+		}
+		// the role may have changed while the description was being written
+		if roleOf(p) < role.Builder {
+			p.Send("빌더 권한이 없어 설명을 저장하지 않았습니다.")
+			return
+		}
+		g.describe(p, e, func(err error) {
+			if err != nil {
+				_ = buildFailed(p, err)
+				return
+			}
+			p.Send(josa.Format("{name}의 설명을 바꿨습니다.", "name", markup.Escape(e.name)))
+		})
+	case ".취소":
+		g.setEditor(p, nil)
+		p.Send("편집을 취소했습니다.")
+	case ".보기":
+		if len(e.lines) == 0 {
+			p.Send("아직 쓴 내용이 없습니다.")
+			return
+		}
+		lines := make([]string, 0, len(e.lines))
+		for i, l := range e.lines {
+			lines = append(lines, "{K}"+strconv.Itoa(i+1)+"{x} "+markup.Escape(l))
+		}
+		p.Send(strings.Join(lines, "\n"))
+	case ".지움":
+		if len(e.lines) > 0 {
+			e.lines = e.lines[:len(e.lines)-1]
+		}
+		p.Send(strconv.Itoa(len(e.lines)) + "줄 남았습니다.")
+	default:
+		e.lines = append(e.lines, strings.TrimRight(line, " \t"))
+	}
+}
+
+func (g *Game) describe(p *Player, e *editor, done func(err error)) {
+	text := strings.Join(e.lines, "\n")
+	if e.room != "" {
+		room, ok := g.world.Room(e.room)
+		if !ok {
+			done(ErrNotInFile)
+			return
+		}
+		g.build(p, e.room+" 설명 편집", []string{room.Area}, func(areas map[string]*world.Area) error {
+			r, err := roomIn(areas[room.Area], room.ID)
+			if err != nil {
+				return err
+			}
+			r.Description = text
+			return nil
+		}, done)
+		return
+	}
+	t, ok := g.world.Items[e.item]
+	if !ok {
+		done(ErrNotInFile)
+		return
+	}
+	g.build(p, e.item+" 설명 편집", []string{t.Area}, func(areas map[string]*world.Area) error {
+		i, err := itemIn(areas[t.Area], t.ID)
+		if err != nil {
+			return err
+		}
+		i.Description = text
+		return nil
+	}, done)
+}
+
+// 방만들기 adds a room to the area of the builder: "작은 창고 방만들기". With a direction the room
+// is linked to the one of the builder both ways: "북에 작은 창고 방만들기".
+var _ = registerFor(role.Builder, "방만들기", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("어떤 방을 만들까요? 예: 작은 창고 방만들기, 북에 작은 창고 방만들기")
+		return nil
+	}
+	here, ok := g.world.Room(p.Room())
+	if !ok {
+		return nil
+	}
+	dir := a.into
+	if _, ok := here.Exits[dir]; ok {
+		p.Send("이미 " + markup.Escape(dir) + " 출구가 있습니다.")
+		return nil
+	}
+
+	id := g.freeID(here.Area, "room")
+	g.build(player, id+" 방 만들기", []string{here.Area}, func(areas map[string]*world.Area) error {
+		area := areas[here.Area]
+		room := &world.Room{ID: id, Name: a.object, Description: noDescription}
+		if dir != "" {
+			from, err := roomIn(area, here.ID)
+			if err != nil {
+				return err
+			}
+			setExit(from, dir, id)
+			if back, ok := opposite[dir]; ok {
+				setExit(room, back, here.ID)
+			}
+		}
+		area.Rooms = append(area.Rooms, room)
+		return nil
+	}, func(err error) {
+		if err != nil {
+			_ = buildFailed(p, err)
+			return
+		}
+		p.Send(josa.Format("{name:을/를} 만들었습니다: {id}", "name", markup.Escape(a.object), "id", id))
+	})
+	return nil
+})
+
+// 출구연결 links the room of the builder to another room: "북 town-inn 출구연결". The other room
+// gets the way back when it has no exit in that direction yet. Without a room the exit is
+// removed, together with the way back: "북 출구연결".
+var _ = registerFor(role.Builder, "출구연결", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	if len(args) == 0 || len(args) > 2 {
+		p.Send("어느 쪽을 어디로 이을까요? 예: 북 town-inn 출구연결, 출구를 없애려면 북 출구연결")
+		return nil
+	}
+	here, ok := g.world.Room(p.Room())
+	if !ok {
+		return nil
+	}
+	dir := args[0]
+	back, twoWay := opposite[dir]
+
+	if len(args) == 1 {
+		to, ok := here.Exits[dir]
+		if !ok {
+			p.Send("그런 출구가 없습니다: " + markup.Escape(dir))
+			return nil
+		}
+		areas := []string{here.Area}
+		there, ok := g.world.Room(to)
+		twoWay = twoWay && ok && there.Exits[back] == here.ID
+		if twoWay {
+			areas = append(areas, there.Area)
+		}
+		g.build(player, here.ID+"의 "+dir+" 출구 없애기", areas, func(areas map[string]*world.Area) error {
+			from, err := roomIn(areas[here.Area], here.ID)
+			if err != nil {
+				return err
+			}
+			delete(from.Exits, dir)
+			if twoWay {
+				other, err := roomIn(areas[there.Area], there.ID)
+				if err != nil {
+					return err
+				}
+				delete(other.Exits, back)
+			}
+			return nil
+		}, func(err error) {
+			if err != nil {
+				_ = buildFailed(p, err)
+				return
+			}
+			p.Send(markup.Escape(dir) + " 출구를 없앴습니다.")
+		})
+		return nil
+	}
+
+	there, ok := g.world.Room(args[1])
+	if !ok {
+		p.Send("그런 방은 없습니다: " + markup.Escape(args[1]))
+		return nil
+	}
+	_, taken := there.Exits[back]
+	twoWay = twoWay && !taken
+	areas := []string{here.Area}
+	if twoWay {
+		areas = append(areas, there.Area)
+	}
+	g.build(player, here.ID+"의 "+dir+" 출구를 "+there.ID+"에 잇기", areas, func(areas map[string]*world.Area) error {
+		from, err := roomIn(areas[here.Area], here.ID)
+		if err != nil {
+			return err
+		}
+		setExit(from, dir, there.ID)
+		if twoWay {
+			other, err := roomIn(areas[there.Area], there.ID)
+			if err != nil {
+				return err
+			}
+			setExit(other, back, here.ID)
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			_ = buildFailed(p, err)
+			return
+		}
+		msg := markup.Escape(dir) + " 출구를 " + markup.Escape(there.Name) + "에 이었습니다."
+		if twoWay {
+			msg += " " + markup.Escape(back) + " 출구로 돌아올 수 있습니다."
+		}
+		p.Send(msg)
+	})
+	return nil
+})
+
+// 설명편집 opens the editor for the description of the room, or of an item named by its id or
+// by what the builder sees: "설명편집", "막대기 설명편집".
+var _ = registerFor(role.Builder, "설명편집", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	e := &editor{}
+	var current string
+	word := parseArgs(args).object
+	if word == "" {
+		room, ok := g.world.Room(p.Room())
+		if !ok {
+			return nil
+		}
+		e.room, e.name, current = room.ID, room.Name, room.Description
+	} else {
+		t, ok := g.world.Items[word]
+		if !ok {
+			i, found := p.Inventory().FindAny(word)
+			if !found {
+				i, found = item.Find(g.floor[p.Room()], word)
+			}
+			if found {
+				t, ok = g.world.Items[i.Template]
+			}
+		}
+		if !ok {
+			p.Send("그런 것은 여기 없습니다: " + markup.Escape(word))
+			return nil
+		}
+		e.item, e.name, current = t.ID, t.Name, t.Description
+	}
+
+	g.setEditor(player, e)
+	p.Send(josa.Format("{W}{name}의 설명 편집{x}", "name", markup.Escape(e.name)) + "\n지금 설명:\n" +
+		markup.Escape(current) + "\n" + editorHelp)
+	return nil
+})
+
+// 아이템생성 adds an item template to the area of the builder and gives the builder one of it:
+// "낡은 검 아이템생성".
+var _ = registerFor(role.Builder, "아이템생성", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	name := parseArgs(args).object
+	if name == "" {
+		p.Send("어떤 아이템을 만들까요? 예: 낡은 검 아이템생성")
+		return nil
+	}
+	here, ok := g.world.Room(p.Room())
+	if !ok {
+		return nil
+	}
+
+	id := g.freeID(here.Area, "item")
+	g.build(player, id+" 아이템 만들기", []string{here.Area}, func(areas map[string]*world.Area) error {
+		t := &world.ItemTemplate{ID: id, Name: name, Description: noDescription}
+		if words := strings.Fields(name); len(words) > 1 {
+			t.Keywords = words
+		}
+		areas[here.Area].Items = append(areas[here.Area].Items, t)
+		return nil
+	}, func(err error) {
+		if err != nil {
+			_ = buildFailed(p, err)
+			return
+		}
+		player.inventory.Add(item.New(g.world.Items[id]))
+		p.Send(josa.Format("{name:을/를} 만들었습니다: {id}", "name", markup.Escape(name), "id", id))
+	})
+	return nil
+})
+
+var _ = registerFor(role.Builder, "되돌리기", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	g.undo(player, func(what string, err error) {
+		if err != nil {
+			_ = buildFailed(p, err)
+			return
+		}
+		p.Send("되돌렸습니다: " + markup.Escape(what))
+	})
+	return nil
+})
@@ -0,0 +1,400 @@
+package game
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/zrma/mud/chat"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/role"
+)
+
+var (
+	ErrNoPlayer = errors.New("no such player")
+	ErrIgnored  = errors.New("ignored by the player")
+	ErrNoReply  = errors.New("no tell to reply to")
+)
+
+// Message sends msg from the player of a session to target, or to a channel when target is
+// empty. The channel defaults to 잡담.
+func (g *Game) Message(token, channel, target, msg string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	p, ok := g.players[token]
+	if !ok {
+		return errors.New("invalid session key")
+	}
+	if target != "" {
+		return g.tell(p, target, msg)
+	}
+	if channel == "" {
+		channel = chat.Gossip
+	}
+	return g.chat(p, channel, msg)
+}
+
+// chat delivers a message to everyone on a channel who didn't mute it or ignore the sender.
+func (g *Game) chat(p *Player, channel, msg string) error {
+	if !p.character.Chat.Joined(channel) {
+		return chat.ErrNotJoined
+	}
+	if err := g.speak(p, msg); err != nil {
+		return err
+	}
+	line := "{Y}[" + markup.Escape(channel) + "]{x} {C}" + markup.Escape(p.Name()) + "{x}: " +
+		markup.Escape(msg)
+	for _, other := range g.sortedPlayers() {
+		if other == p || other.character.Chat.Hears(channel, p.Name()) {
+			other.Send(line)
+		}
+	}
+	g.remember(g.channelHistory, channel, chat.Entry{Channel: channel, Sender: p.Name(), Text: msg})
+	return nil
+}
+
+func (g *Game) tell(p *Player, name, msg string) error {
+	to, ok := g.playerNamed(name)
+	if !ok {
+		return ErrNoPlayer
+	}
+	if to.character.Chat.Ignores(p.Name()) {
+		return ErrIgnored
+	}
+	if err := g.speak(p, msg); err != nil {
+		return err
+	}
+	to.Send("{M}" + markup.Escape(p.Name()) + "님의 귓속말{x}: " + markup.Escape(msg))
+	p.Send("{M}" + recipient(to) + " 귓속말{x}: " + markup.Escape(msg))
+	to.replyTo = p.Name()
+	return nil
+}
+
+func (g *Game) playerNamed(name string) (*Player, bool) {
+	for _, p := range g.sortedPlayers() {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func chatFailed(p Actor, err error) error {
+	switch err {
+	case chat.ErrNotJoined:
+		p.Send("그 채널에 참여하고 있지 않습니다.")
+	case chat.ErrNoGuild:
+		p.Send("참여한 길드 채널이 없습니다.")
+	case chat.ErrNotMember:
+		p.Send("그 길드의 길드원만 참여할 수 있습니다.")
+	case chat.ErrName:
+		p.Send("채널 이름으로 쓸 수 없습니다.")
+	case ErrNoPlayer:
+		p.Send("그런 사람은 접속해 있지 않습니다.")
+	case ErrIgnored:
+		p.Send("상대가 당신의 말을 듣지 않고 있습니다.")
+	case ErrNoReply:
+		p.Send("대답할 귓속말이 없습니다.")
+	case ErrMuted:
+		// speak told the player already
+	default:
+		return err
+	}
+	return nil
+}
+
+func channelCommand(channel string) handler {
+	return func(g *Game, p Actor, args []string) error {
+		player, ok := p.(*Player)
+		if !ok {
+			return nil
+		}
+		if len(args) == 0 {
+			p.Send("무슨 말을 할까요?")
+			return nil
+		}
+		return chatFailed(p, g.chat(player, channel, strings.Join(args, " ")))
+	}
+}
+
+var _ = register(chat.Gossip, channelCommand(chat.Gossip))
+
+var _ = register(chat.Trade, channelCommand(chat.Trade))
+
+var _ = register("길드", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	guild := player.character.Chat.Guild
+	if !player.character.Chat.Joined(guild) {
+		return chatFailed(p, chat.ErrNoGuild)
+	}
+	return channelCommand(guild)(g, p, args)
+})
+
+// officerOf tells whether p may add players to a guild and remove them from it, as an officer of
+// the guild or as a moderator.
+func officerOf(p *Player, guild string) bool {
+	if roleOf(p) >= role.Moderator {
+		return true
+	}
+	settings := p.character.Chat
+	return settings.Officer && guild != "" && settings.Member == guild
+}
+
+// 길드가입 makes a player a member of a guild: "철수를 용사단에 길드가입". Officers add players
+// to their own guild, moderators to any.
+var _ = register("길드가입", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	a := parseArgs(args)
+	guild := a.into
+	if guild == "" {
+		guild = player.character.Chat.Member
+	}
+	if a.object == "" || guild == "" {
+		p.Send("누구를 어느 길드에 넣을까요? 예: 철수를 용사단에 길드가입")
+		return nil
+	}
+	to, ok := g.playerNamed(a.object)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	if !officerOf(player, guild) {
+		p.Send("길드 간부만 길드원을 받을 수 있습니다.")
+		return nil
+	}
+	if member := to.character.Chat.Member; member != "" && member != guild && !officerOf(player, member) {
+		p.Send(markup.Escape(to.Name()) + "님은 이미 다른 길드의 길드원입니다.")
+		return nil
+	}
+	if err := to.character.Chat.SetGuild(guild, false); err != nil {
+		return chatFailed(p, err)
+	}
+	if roleOf(p) >= role.Moderator {
+		g.record(p, "guild", to.Name(), guild)
+	}
+	to.Send(markup.Escape(guild) + " 길드의 길드원이 되었습니다.")
+	if to != player {
+		p.Send(markup.Escape(to.Name()) + "님을 " + markup.Escape(guild) + " 길드에 넣었습니다.")
+	}
+	return nil
+})
+
+// 길드제명 takes a player out of their guild: "철수 길드제명". Players may leave their guild
+// themselves.
+var _ = register("길드제명", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("누구를 길드에서 내보낼까요? 예: 철수 길드제명")
+		return nil
+	}
+	to, ok := g.playerNamed(a.object)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	guild := to.character.Chat.Member
+	if guild == "" {
+		p.Send(markup.Escape(to.Name()) + "님은 길드원이 아닙니다.")
+		return nil
+	}
+	// officers don't remove each other
+	if to != player && (!officerOf(player, guild) || to.character.Chat.Officer && roleOf(p) < role.Moderator) {
+		p.Send("길드 간부만 길드원을 내보낼 수 있습니다.")
+		return nil
+	}
+	if err := to.character.Chat.SetGuild("", false); err != nil {
+		return chatFailed(p, err)
+	}
+	if to != player && roleOf(p) >= role.Moderator {
+		g.record(p, "unguild", to.Name(), guild)
+	}
+	to.Send(markup.Escape(guild) + " 길드에서 나왔습니다.")
+	if to != player {
+		p.Send(markup.Escape(to.Name()) + "님을 " + markup.Escape(guild) + " 길드에서 내보냈습니다.")
+	}
+	return nil
+})
+
+// 길드간부 makes a member of a guild an officer of it, or an officer a member again: "철수
+// 길드간부".
+var _ = registerFor(role.Moderator, "길드간부", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("누구를 길드 간부로 정할까요? 예: 철수 길드간부")
+		return nil
+	}
+	to, ok := g.playerNamed(a.object)
+	if !ok {
+		return chatFailed(p, ErrNoPlayer)
+	}
+	settings := to.character.Chat
+	if settings.Member == "" {
+		p.Send(markup.Escape(to.Name()) + "님은 길드원이 아닙니다.")
+		return nil
+	}
+	settings.Officer = !settings.Officer
+	if settings.Officer {
+		g.record(p, "guild officer", to.Name(), settings.Member)
+		to.Send(markup.Escape(settings.Member) + " 길드의 간부가 되었습니다.")
+		p.Send(markup.Escape(to.Name()) + "님을 " + markup.Escape(settings.Member) + " 길드의 간부로 정했습니다.")
+	} else {
+		g.record(p, "guild member", to.Name(), settings.Member)
+		to.Send(markup.Escape(settings.Member) + " 길드의 간부에서 물러났습니다.")
+		p.Send(markup.Escape(to.Name()) + "님을 " + markup.Escape(settings.Member) + " 길드의 길드원으로 돌렸습니다.")
+	}
+	return nil
+})
+
+var _ = register("채널", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	settings := player.character.Chat
+
+	lines := []string{"{W}참여 중인 채널{x}"}
+	for _, c := range settings.Channels() {
+		line := "  " + markup.Escape(c)
+		switch {
+		case chat.IsPublic(c):
+		case settings.Officer:
+			line += " (길드 간부)"
+		default:
+			line += " (길드)"
+		}
+		if settings.IsMuted(c) {
+			line += " - 음소거"
+		}
+		lines = append(lines, line)
+	}
+	if len(settings.Channels()) == 0 {
+		lines = append(lines, "  없음")
+	}
+	lines = append(lines, "'<채널> 참여', '<채널> 탈퇴', '<채널> 음소거'로 바꿀 수 있습니다.")
+	p.Send(strings.Join(lines, "\n"))
+	return nil
+})
+
+var _ = register("참여", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	channel := parseArgs(args).object
+	if channel == "" {
+		p.Send("어떤 채널에 참여할까요?")
+		return nil
+	}
+	if err := player.character.Chat.Join(channel); err != nil {
+		return chatFailed(p, err)
+	}
+	p.Send(markup.Escape(channel) + " 채널에 참여했습니다.")
+	return nil
+})
+
+var _ = register("탈퇴", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	channel := parseArgs(args).object
+	if channel == "" {
+		p.Send("어떤 채널에서 나갈까요?")
+		return nil
+	}
+	if err := player.character.Chat.Leave(channel); err != nil {
+		return chatFailed(p, err)
+	}
+	p.Send(markup.Escape(channel) + " 채널에서 나왔습니다.")
+	return nil
+})
+
+var _ = register("음소거", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	channel := parseArgs(args).object
+	if channel == "" {
+		p.Send("어떤 채널을 음소거할까요?")
+		return nil
+	}
+	muted, err := player.character.Chat.Mute(channel)
+	if err != nil {
+		return chatFailed(p, err)
+	}
+	if muted {
+		p.Send(markup.Escape(channel) + " 채널을 음소거했습니다.")
+	} else {
+		p.Send(markup.Escape(channel) + " 채널의 음소거를 풀었습니다.")
+	}
+	return nil
+})
+
+// 귓 takes the name of the player first: "철수에게 안녕 귓". The rest of the line is the message
+// as it is, particles and all.
+var _ = register("귓", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	var name string
+	if len(args) > 0 {
+		name = parseArgs(args[:1]).to
+	}
+	if name == "" || len(args) < 2 {
+		p.Send("누구에게 무슨 말을 할까요? 예: 철수에게 안녕 귓")
+		return nil
+	}
+	return chatFailed(p, g.tell(player, name, strings.Join(args[1:], " ")))
+})
+
+var _ = register("대답", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	if len(args) == 0 {
+		p.Send("무슨 말을 할까요?")
+		return nil
+	}
+	if player.replyTo == "" {
+		return chatFailed(p, ErrNoReply)
+	}
+	return chatFailed(p, g.tell(player, player.replyTo, strings.Join(args, " ")))
+})
+
+var _ = register("무시", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	settings := player.character.Chat
+	name := parseArgs(args).object
+	if name == "" {
+		if len(settings.Ignore) == 0 {
+			p.Send("무시하는 사람이 없습니다.")
+		} else {
+			p.Send("무시하는 사람: " + markup.Escape(strings.Join(settings.Ignore, ", ")))
+		}
+		return nil
+	}
+	if name == p.Name() {
+		p.Send("자신을 무시할 수는 없습니다.")
+		return nil
+	}
+	if settings.ToggleIgnore(name) {
+		p.Send(markup.Escape(name) + "님의 말을 더 이상 듣지 않습니다.")
+	} else {
+		p.Send(markup.Escape(name) + "님의 말을 다시 듣습니다.")
+	}
+	return nil
+})
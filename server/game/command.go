@@ -0,0 +1,160 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/role"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrPermission     = errors.New("permission denied")
+)
+
+type handler func(g *Game, p Actor, args []string) error
+
+type command struct {
+	Word string
+	Func handler
+	// Role is the least role that may use the command.
+	Role role.Role
+}
+
+var commands map[string]*command
+
+// register adds a command every player may use.
+func register(word string, f handler) error {
+	return registerFor(role.Player, word, f)
+}
+
+// registerFor adds a command only the given role and the roles above it may use.
+func registerFor(r role.Role, word string, f handler) error {
+	if commands == nil {
+		commands = make(map[string]*command)
+	}
+
+	if _, ok := commands[word]; ok {
+		return errors.New(fmt.Sprintln("already registered command", word))
+	}
+
+	commands[word] = &command{
+		Word: word,
+		Func: f,
+		Role: r,
+	}
+	return nil
+}
+
+// Words lists the commands the server understands for every player, for client side completion.
+func Words() []string {
+	return words(role.Player)
+}
+
+// wordsFor lists the commands an actor may use.
+func wordsFor(p Actor) []string {
+	return words(roleOf(p))
+}
+
+func words(r role.Role) []string {
+	words := make([]string, 0, len(commands))
+	for word, cmd := range commands {
+		if cmd.Role <= r {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// roleOf is the role of a player. Mobs are players in that sense.
+func roleOf(p Actor) role.Role {
+	if player, ok := p.(*Player); ok {
+		return player.role
+	}
+	return role.Player
+}
+
+// Role returns the role of the player of a session.
+func (g *Game) Role(token string) (role.Role, bool) {
+	g.Lock()
+	defer g.Unlock()
+
+	p, ok := g.players[token]
+	if !ok {
+		return role.Player, false
+	}
+	return p.role, true
+}
+
+// Execute runs a command line for the player of a session. Like on the client the command word
+// comes last and the words in front of it are its arguments.
+func (g *Game) Execute(token, line string) error {
+	g.Lock()
+	defer g.Unlock()
+
+	p, ok := g.players[token]
+	if !ok {
+		return errors.New("invalid session key")
+	}
+	if p.editor != nil {
+		g.edit(p, line)
+		return nil
+	}
+	return g.execute(p, line)
+}
+
+// execute runs a command line as any actor. The game lock must be held.
+func (g *Game) execute(p Actor, line string) error {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return nil
+	}
+	args, word := words[:len(words)-1], words[len(words)-1]
+
+	cmd, ok := commands[word]
+	if !ok {
+		// the name of an exit moves the player, like "북"
+		if len(args) == 0 && g.move(p, word) {
+			return nil
+		}
+		// and so do the socials of the world, like "웃어"
+		if s, ok := g.world.Socials[word]; ok {
+			g.social(p, s, args)
+			return nil
+		}
+		return ErrUnknownCommand
+	}
+	if cmd.Role > roleOf(p) {
+		return ErrPermission
+	}
+	return cmd.Func(g, p, args)
+}
+
+// 리로드 reads the area files again. Like the edits of builders it goes through Reload, which
+// reads them without the game lock, and tells the builder how it went once it is done.
+var _ = registerFor(role.Builder, "리로드", func(g *Game, p Actor, args []string) error {
+	go func() {
+		err := g.Reload()
+
+		g.Lock()
+		defer g.Unlock()
+
+		if err != nil {
+			reloadFailed(p, err)
+			return
+		}
+		g.record(p, "reload", "", "")
+		p.Send("월드를 다시 불러왔습니다.")
+	}()
+	return nil
+})
+
+// reloadFailed shows a builder why the area files weren't applied.
+func reloadFailed(p Actor, err error) {
+	p.Send("{R}월드를 다시 불러오지 못했습니다.{x}")
+	for _, line := range strings.Split(err.Error(), "\n") {
+		p.Send(markup.Escape(line))
+	}
+}
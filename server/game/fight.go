@@ -0,0 +1,215 @@
+package game
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/server/combat"
+	"github.com/zrma/mud/server/script"
+	"github.com/zrma/mud/world"
+)
+
+const (
+	expPerLevel  = 30
+	goldPerLevel = 5
+	deathExpLoss = 10 // percent
+)
+
+func fighterName(f combat.Fighter) string {
+	return f.Character().Name
+}
+
+func fighterRoom(f combat.Fighter) string {
+	if a, ok := f.(Actor); ok {
+		return a.Room()
+	}
+	return ""
+}
+
+func sendFighter(f combat.Fighter, msg string) {
+	if a, ok := f.(Actor); ok {
+		a.Send(msg)
+	}
+}
+
+// combatRound resolves one round of every fight and tells everyone in the rooms about it.
+func (g *Game) combatRound() {
+	for _, hit := range g.combat.Round() {
+		a, t := markup.Escape(fighterName(hit.Attacker)), markup.Escape(fighterName(hit.Target))
+		room := fighterRoom(hit.Attacker)
+
+		if hit.Missed {
+			sendFighter(hit.Attacker, "당신의 공격이 "+t+"에게서 빗나갔습니다.")
+			sendFighter(hit.Target, a+"의 공격을 피했습니다.")
+			g.sendBystanders(room, a+"의 공격이 "+t+"에게서 빗나갔습니다.", hit.Attacker, hit.Target)
+		} else {
+			damage := strconv.Itoa(hit.Damage)
+			sendFighter(hit.Attacker, "{y}당신의 공격이 "+t+"에게 "+damage+"의 피해를 입혔습니다.{x}")
+			sendFighter(hit.Target, "{r}"+a+"의 공격으로 "+damage+"의 피해를 입었습니다.{x}")
+			g.sendBystanders(room, a+"의 공격이 "+t+"에게 "+damage+"의 피해를 입혔습니다.", hit.Attacker, hit.Target)
+		}
+
+		for _, f := range []combat.Fighter{hit.Attacker, hit.Target} {
+			if p, ok := f.(*Player); ok {
+				g.sendStats(p)
+			}
+		}
+		if hit.Killed {
+			g.kill(hit.Target, hit.Attacker)
+		}
+	}
+}
+
+func (g *Game) sendBystanders(room, msg string, except ...combat.Fighter) {
+	for _, p := range g.playersIn(room) {
+		skip := false
+		for _, f := range except {
+			if f == combat.Fighter(p) {
+				skip = true
+			}
+		}
+		if !skip {
+			p.Send(msg)
+		}
+	}
+}
+
+func (g *Game) kill(victim, killer combat.Fighter) {
+	// Round only takes out those it killed itself, not those killed by scripts
+	g.combat.Disengage(victim)
+
+	room := fighterRoom(victim)
+	g.sendBystanders(room, josa.Format("{R}{victim:이/가} 쓰러졌습니다.{x}",
+		"victim", markup.Escape(fighterName(victim))), victim)
+
+	var killedBy interface{}
+	if a, ok := killer.(Actor); ok {
+		killedBy = ref(a)
+	}
+	if m, ok := victim.(*Mob); ok {
+		g.mobHook(m, script.OnDeath, killedBy)
+	}
+	g.roomHook(room, script.OnDeath, ref(victim.(Actor)), killedBy)
+
+	if m, ok := victim.(*Mob); ok && killedBy != nil {
+		g.emit(killer.(Actor), world.StepKill, m.Template)
+	}
+
+	switch v := victim.(type) {
+	case *Mob:
+		g.removeMob(v)
+		if p, ok := killer.(*Player); ok {
+			g.reward(p, v)
+		}
+	case *Player:
+		g.respawn(v)
+	}
+}
+
+func (g *Game) reward(p *Player, m *Mob) {
+	exp := expPerLevel * m.Character().Level
+	gold := goldPerLevel * m.Character().Level
+	p.Character().Gold += gold
+	p.Send(josa.Format("경험치 {exp:과/와} {gold} 골드를 얻었습니다.",
+		"exp", strconv.Itoa(exp), "gold", strconv.Itoa(gold)))
+	if levels := p.Character().GainExp(exp); levels > 0 {
+		p.Send("{Y}레벨이 올랐습니다! 이제 레벨 " + strconv.Itoa(p.Character().Level) + "입니다.{x}")
+	}
+	g.sendStats(p)
+}
+
+// respawn brings a dead player back in the start room, at the cost of some experience.
+func (g *Game) respawn(p *Player) {
+	c := p.Character()
+	c.Exp -= c.Exp * deathExpLoss / 100
+	c.HP = c.MaxHP / 2
+	if c.HP < 1 {
+		c.HP = 1
+	}
+
+	p.Send("{R}당신은 쓰러졌습니다...{x}")
+	p.setRoom(g.world.Start)
+	p.Send("정신을 차려 보니 " + g.world.StartRoom().Name + "입니다.")
+	g.sendRoom(p.Room(), subject(p)+" 비틀거리며 나타났습니다.", p)
+	g.look(p)
+	g.sendStats(p)
+	g.enter(p)
+}
+
+func (g *Game) engage(a, target Actor) {
+	g.combat.Engage(a, target)
+	a.Send(josa.Format("{target:을/를} 공격합니다!", "target", markup.Escape(target.Name())))
+	target.Send("{R}" + subject(a) + " 당신을 공격합니다!{x}")
+	g.sendBystanders(a.Room(), josa.Format("{actor} {target:을/를} 공격합니다!",
+		"actor", subject(a), "target", markup.Escape(target.Name())), a, target)
+}
+
+var _ = register("공격", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		if t, ok := g.combat.Target(p); ok {
+			p.Send(josa.Format("{target:과/와} 싸우고 있습니다.", "target", fighterName(t)))
+			return nil
+		}
+		p.Send("누구를 공격할까요?")
+		return nil
+	}
+
+	for _, m := range g.mobsIn(p.Room()) {
+		if m != p && m.Matches(a.object) {
+			g.engage(p, m)
+			return nil
+		}
+	}
+	for _, other := range g.playersIn(p.Room()) {
+		if other == p || !matches(a.object, other.Name(), nil) {
+			continue
+		}
+		// mobs may attack players, players may not attack each other
+		if _, ok := p.(*Player); ok {
+			p.Send("다른 플레이어는 공격할 수 없습니다.")
+			return nil
+		}
+		g.engage(p, other)
+		return nil
+	}
+	p.Send("그런 상대는 보이지 않습니다: " + a.object)
+	return nil
+})
+
+var _ = register("방어", func(g *Game, p Actor, args []string) error {
+	if !g.combat.Fighting(p) {
+		p.Send("싸우고 있지 않습니다.")
+		return nil
+	}
+	g.combat.Defend(p)
+	p.Send("방어 자세를 취합니다.")
+	return nil
+})
+
+var _ = register("도망", func(g *Game, p Actor, args []string) error {
+	if !g.combat.Fighting(p) {
+		p.Send("싸우고 있지 않습니다.")
+		return nil
+	}
+	room, ok := g.world.Room(p.Room())
+	if !ok || len(room.Exits) == 0 {
+		p.Send("도망칠 곳이 없습니다!")
+		return nil
+	}
+	if !g.combat.Flee(p) {
+		p.Send("도망치지 못했습니다!")
+		return nil
+	}
+
+	exits := make([]string, 0, len(room.Exits))
+	for dir := range room.Exits {
+		exits = append(exits, dir)
+	}
+	sort.Strings(exits)
+	p.Send("{y}도망쳤습니다!{x}")
+	g.move(p, exits[g.rng.Intn(len(exits))])
+	return nil
+})
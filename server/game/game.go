@@ -0,0 +1,454 @@
+package game
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zrma/mud/character"
+	"github.com/zrma/mud/chat"
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/logging"
+	"github.com/zrma/mud/quest"
+	"github.com/zrma/mud/role"
+	"github.com/zrma/mud/server/audit"
+	"github.com/zrma/mud/server/clock"
+	"github.com/zrma/mud/server/combat"
+	"github.com/zrma/mud/server/limit"
+	"github.com/zrma/mud/server/scheduler"
+	"github.com/zrma/mud/server/script"
+	"github.com/zrma/mud/server/session"
+	"github.com/zrma/mud/store"
+	"github.com/zrma/mud/world"
+)
+
+type Config struct {
+	WorldDir string
+	// Seed makes every random roll of the game reproducible. Zero picks a seed from the clock.
+	Seed int64
+	// TickRate is how often the game loop runs due events. Zero means 100ms.
+	TickRate time.Duration
+	// Clock defaults to the wall clock. With a clock.Fake the game only moves when it is advanced.
+	Clock clock.Clock
+	// Store keeps players and the world across restarts. Without one nothing is saved.
+	Store store.Store
+	// SaveInterval is how often everything is saved. Zero means five minutes.
+	SaveInterval time.Duration
+	// Limits mute players who keep saying the same thing. The server takes its request rates
+	// from them too.
+	Limits limit.Config
+	// Audit records every action of an admin. Without one they are only logged.
+	Audit *audit.Log
+}
+
+func New(logger logging.Logger, cfg Config) (*Game, error) {
+	w, err := world.Load(cfg.WorldDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := script.CheckWorld(w); err != nil {
+		return nil, err
+	}
+	logger.Info(
+		"world loaded",
+		"dir", cfg.WorldDir,
+		"areas", len(w.Areas),
+		"rooms", len(w.Rooms),
+	)
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	c := cfg.Clock
+	if c == nil {
+		c = clock.Real{}
+	}
+	tickRate := cfg.TickRate
+	if tickRate <= 0 {
+		tickRate = defaultTickRate
+	}
+	saveInterval := cfg.SaveInterval
+	if saveInterval <= 0 {
+		saveInterval = defaultSaveInterval
+	}
+
+	g := Game{
+		logger:    logger,
+		worldDir:  cfg.WorldDir,
+		world:     w,
+		rng:       rng,
+		combat:    combat.New(rng),
+		clock:     c,
+		tickRate:  tickRate,
+		scheduler: scheduler.New(c.Now()),
+		store:     cfg.Store,
+		saveEvery: saveInterval,
+		limits:    cfg.Limits,
+		audit:     cfg.Audit,
+		bans:      store.NewBans(),
+		players:   make(map[string]*Player),
+		floor:     make(map[string][]*item.Item),
+
+		channelHistory: make(map[string]*chat.History),
+		roomHistory:    make(map[string]*chat.History),
+	}
+	g.loadScripts()
+	g.restoreWorld()
+	g.spawnItems()
+	g.spawnMobs()
+	if err := g.startSystems(); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Game is the state of the running world. Every change goes through the game lock, so commands
+// and reloads never see each other half done.
+type Game struct {
+	sync.Mutex
+
+	logger   logging.Logger
+	worldDir string
+	world    *world.World
+	rng      *rand.Rand
+	combat   *combat.Combat
+
+	// building serializes the edits of builders and reloads, which read and write the area files
+	// without holding the game lock. It is never taken while holding the game lock.
+	building sync.Mutex
+	// editing is set while an edit of a builder is being applied. Other edits are refused until
+	// it is done, they would be made against the world from before it.
+	editing bool
+
+	clock     clock.Clock
+	tickRate  time.Duration
+	scheduler *scheduler.Scheduler
+	scripts   *script.Engine
+	store     store.Store
+	saveEvery time.Duration
+	limits    limit.Config
+	audit     *audit.Log
+	bans      *store.Bans
+
+	players map[string]*Player
+	floor   map[string][]*item.Item
+	mobs    []*Mob
+	spawned map[string]bool
+	weather int
+
+	channelHistory map[string]*chat.History
+	roomHistory    map[string]*chat.History
+}
+
+type Player struct {
+	name      string
+	room      string
+	inventory *item.Inventory
+	character *character.Character
+	session   *session.Session
+	// address is the IP the player connected from.
+	address string
+	role    role.Role
+	// replyTo is who sent the last tell, for 대답.
+	replyTo string
+	// mutedUntil is when the player may talk again after a mute.
+	mutedUntil time.Time
+	repeats    limit.Repeats
+	// editor takes the lines of the player while a description is being written.
+	editor *editor
+	// edits are the last changes of a builder, for 되돌리기.
+	edits []edit
+}
+
+func (p *Player) Name() string {
+	return p.name
+}
+
+func (p *Player) Room() string {
+	return p.room
+}
+
+// Role is what the account of the player may do.
+func (p *Player) Role() role.Role {
+	return p.role
+}
+
+func (p *Player) setRoom(room string) {
+	p.room = room
+}
+
+func (p *Player) Inventory() *item.Inventory {
+	return p.inventory
+}
+
+func (p *Player) Character() *character.Character {
+	return p.character
+}
+
+func (p *Player) Damage() int {
+	if w, ok := p.Inventory().Equipment[world.SlotWeapon]; ok {
+		return w.Damage()
+	}
+	return 0
+}
+
+func (p *Player) Armor() int {
+	armor := 0
+	for _, i := range p.Inventory().Equipment {
+		armor += i.Armor()
+	}
+	return armor
+}
+
+func (p *Player) Send(msg string) {
+	p.session.Put(msg)
+}
+
+func (p *Player) SendEvent(kind event.Kind, v interface{}) error {
+	data, err := event.Encode(v)
+	if err != nil {
+		return err
+	}
+	p.session.PutEvent(string(kind), data)
+	return nil
+}
+
+const startingGold = 50
+
+func newPlayer(name, room string, sess *session.Session) *Player {
+	c := character.New(name)
+	c.Gold = startingGold
+	c.Quests = quest.NewLog()
+	c.Chat = chat.NewSettings()
+	return &Player{
+		name:      name,
+		room:      room,
+		inventory: item.NewInventory(),
+		character: c,
+		session:   sess,
+	}
+}
+
+// Join places the player of a new session where it logged out, or in the start room for a new
+// character. Banned accounts and addresses are refused with ErrBanned, a wrong password with
+// ErrPassword and an account that can't be loaded with ErrAccount. A player who is still
+// connected is taken over by the new session, so that a character is never in the game twice.
+func (g *Game) Join(token, name, password, address string, sess *session.Session) (*Player, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.banned(name, address) {
+		g.logger.Info(
+			"banned player refused",
+			"name", name,
+			"address", address,
+		)
+		return nil, ErrBanned
+	}
+	a, err := g.login(name, password)
+	if err != nil {
+		g.logger.Info(
+			"login refused",
+			"name", name,
+			"address", address,
+			"err", err,
+		)
+		return nil, err
+	}
+
+	if p, ok := g.playerNamed(name); ok {
+		g.takeOver(p, token, address, sess)
+		return p, nil
+	}
+
+	p := g.loadPlayer(name, a, sess)
+	p.address = address
+	g.players[token] = p
+	g.sendRoom(p.Room(), subject(p)+" 왔습니다.", p)
+
+	if err := p.SendEvent(event.CommandsKind, event.Commands{Words: wordsFor(p)}); err != nil {
+		g.logger.Warn(
+			"event sending failed",
+			"kind", event.CommandsKind,
+			"err", err,
+		)
+	}
+	g.look(p)
+	g.sendStats(p)
+	g.enter(p)
+	return p, nil
+}
+
+// takeOver moves a connected player to a new session and ends the old one.
+func (g *Game) takeOver(p *Player, token, address string, sess *session.Session) {
+	p.Send("{R}다른 곳에서 접속해 이 접속을 끊습니다.{x}")
+	p.session.Close()
+	for old, other := range g.players {
+		if other == p {
+			delete(g.players, old)
+		}
+	}
+	g.logger.Info(
+		"player taken over",
+		"name", p.Name(),
+		"address", address,
+	)
+
+	p.session = sess
+	p.address = address
+	g.players[token] = p
+	if err := p.SendEvent(event.CommandsKind, event.Commands{Words: wordsFor(p)}); err != nil {
+		g.logger.Warn(
+			"event sending failed",
+			"kind", event.CommandsKind,
+			"err", err,
+		)
+	}
+	p.Send("이전 접속을 이어받았습니다.")
+	g.look(p)
+	g.sendStats(p)
+	if p.editor != nil {
+		g.sendEditor(p)
+		p.Send("설명을 편집하고 있습니다. " + editorHelp)
+	}
+}
+
+func (g *Game) Leave(token string) {
+	g.Lock()
+	defer g.Unlock()
+
+	g.leave(token)
+}
+
+func (g *Game) leave(token string) {
+	p, ok := g.players[token]
+	if !ok {
+		return
+	}
+	delete(g.players, token)
+	g.combat.Disengage(p)
+	g.sendRoom(p.Room(), subject(p)+" 떠났습니다.", p)
+	g.savePlayer(p)
+}
+
+// Reload reads the area files again and swaps the world if they are valid. A broken world is
+// never applied, the validation error is returned instead.
+func (g *Game) Reload() error {
+	g.building.Lock()
+	defer g.building.Unlock()
+
+	w, err := g.load()
+	if err != nil {
+		return err
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.apply(w)
+	return nil
+}
+
+func (g *Game) load() (*world.World, error) {
+	w, err := world.Load(g.worldDir)
+	if err == nil {
+		err = script.CheckWorld(w)
+	}
+	if err != nil {
+		g.logger.Warn(
+			"world reload rejected",
+			"dir", g.worldDir,
+			"err", err,
+		)
+		return nil, err
+	}
+	return w, nil
+}
+
+// apply swaps the world in place. Players whose room disappeared are moved to the start room,
+// items lying there are lost, and items of new rooms are spawned.
+func (g *Game) apply(w *world.World) {
+	g.world = w
+	for id, items := range g.floor {
+		if _, ok := w.Room(id); !ok {
+			delete(g.floor, id)
+			continue
+		}
+		for _, i := range items {
+			i.Bind(w)
+		}
+	}
+	g.loadScripts()
+	g.spawnItems()
+	g.bindMobs()
+
+	for _, p := range g.sortedPlayers() {
+		p.Inventory().Bind(w)
+		if _, ok := w.Room(p.Room()); ok {
+			continue
+		}
+		p.setRoom(w.Start)
+		p.Send(josa.Format("있던 곳이 사라져 {room:으로/로} 옮겨졌습니다.", "room", w.StartRoom().Name))
+		g.look(p)
+	}
+
+	g.logger.Info(
+		"world reloaded",
+		"dir", g.worldDir,
+		"areas", len(w.Areas),
+		"rooms", len(w.Rooms),
+	)
+}
+
+// Watch reloads the world whenever the area files change, until ctx is done. Builders and admins
+// who are online are told when the changed files can't be applied.
+func (g *Game) Watch(ctx context.Context, interval time.Duration) {
+	world.Watch(ctx, g.worldDir, interval, func() {
+		err := g.Reload()
+		if err == nil {
+			return
+		}
+
+		g.Lock()
+		defer g.Unlock()
+
+		for _, p := range g.sortedPlayers() {
+			if p.role >= role.Builder {
+				p.Send("{y}영역 파일이 바뀌었습니다.{x}")
+				reloadFailed(p, err)
+			}
+		}
+	})
+}
+
+// spawnItems puts the items of the area files on the floor of rooms that have no floor yet.
+func (g *Game) spawnItems() {
+	for id, room := range g.world.Rooms {
+		if _, ok := g.floor[id]; ok {
+			continue
+		}
+		items := make([]*item.Item, 0, len(room.Items))
+		for _, t := range room.Items {
+			items = append(items, item.New(g.world.Items[t]))
+		}
+		g.floor[id] = items
+	}
+}
+
+func (g *Game) sortedPlayers() []*Player {
+	players := make([]*Player, 0, len(g.players))
+	for _, p := range g.players {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name() < players[j].Name()
+	})
+	return players
+}
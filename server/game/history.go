@@ -0,0 +1,101 @@
+package game
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/zrma/mud/chat"
+)
+
+const (
+	// historySize is how many messages every channel and room keeps.
+	historySize = 100
+	// historyShown is how many of them 기록 shows.
+	historyShown = 20
+)
+
+// remember adds a message to the history of a channel, or of a room for messages said in one.
+func (g *Game) remember(histories map[string]*chat.History, key string, e chat.Entry) {
+	h, ok := histories[key]
+	if !ok {
+		h = chat.NewHistory(historySize)
+		histories[key] = h
+	}
+	e.Time = g.clock.Now()
+	h.Add(e)
+}
+
+// History returns the last messages the player of a session can read, up to limit. Without a
+// channel they are those of the room and of every channel the player is on, merged.
+func (g *Game) History(token, channel string, limit int) ([]chat.Entry, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	p, ok := g.players[token]
+	if !ok {
+		return nil, errors.New("invalid session key")
+	}
+	return g.history(p, channel, limit)
+}
+
+func (g *Game) history(p *Player, channel string, limit int) ([]chat.Entry, error) {
+	if limit <= 0 {
+		limit = historyShown
+	}
+	if limit > historySize {
+		limit = historySize
+	}
+	settings := p.character.Chat
+
+	var lists [][]chat.Entry
+	last := func(h *chat.History) {
+		if h == nil {
+			return
+		}
+		var entries []chat.Entry
+		for _, e := range h.Last(-1) {
+			if !settings.Ignores(e.Sender) {
+				entries = append(entries, e)
+			}
+		}
+		lists = append(lists, entries)
+	}
+	if channel == "" {
+		last(g.roomHistory[p.Room()])
+		for _, c := range settings.Channels() {
+			if !settings.IsMuted(c) {
+				last(g.channelHistory[c])
+			}
+		}
+	} else {
+		if !settings.Joined(channel) {
+			return nil, chat.ErrNotJoined
+		}
+		last(g.channelHistory[channel])
+	}
+
+	return chat.Merge(limit, lists...), nil
+}
+
+// 기록 shows what was said lately: "기록" in the room and on every channel, "잡담 기록" on one
+// channel.
+var _ = register("기록", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	entries, err := g.history(player, parseArgs(args).object, historyShown)
+	if err != nil {
+		return chatFailed(p, err)
+	}
+	if len(entries) == 0 {
+		p.Send("기록된 대화가 없습니다.")
+		return nil
+	}
+	lines := []string{"{W}최근 대화{x}"}
+	for _, e := range entries {
+		lines = append(lines, e.Markup())
+	}
+	p.Send(strings.Join(lines, "\n"))
+	return nil
+})
@@ -0,0 +1,241 @@
+package game
+
+import (
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/server/render"
+	"github.com/zrma/mud/server/script"
+	"github.com/zrma/mud/world"
+)
+
+// container finds a container the player carries or that lies in the room.
+func (g *Game) container(p Actor, word string) (*item.Item, bool) {
+	if i, ok := p.Inventory().Find(word); ok && i.IsContainer() {
+		return i, true
+	}
+	if i, ok := item.Find(g.floor[p.Room()], word); ok && i.IsContainer() {
+		return i, true
+	}
+	return nil, false
+}
+
+func (g *Game) takeFromFloor(room, word string) (*item.Item, bool) {
+	items := g.floor[room]
+	for idx, i := range items {
+		if i.Matches(word) {
+			g.floor[room] = append(items[:idx:idx], items[idx+1:]...)
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+var _ = register("주워", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("무엇을 주울까요?")
+		return nil
+	}
+
+	if a.from != "" {
+		c, ok := g.container(p, a.from)
+		if !ok {
+			p.Send("그런 것은 보이지 않습니다: " + a.from)
+			return nil
+		}
+		i, ok := c.Take(a.object)
+		if !ok {
+			p.Send(c.Name() + " 안에 그런 것은 없습니다: " + a.object)
+			return nil
+		}
+		p.Inventory().Add(i)
+		p.Send(josa.Format("{container}에서 {item:을/를} 꺼냈습니다.",
+			"container", c.Name(), "item", i.Name()))
+		g.sendRoom(p.Room(), josa.Format("{actor} {container}에서 {item:을/를} 꺼냈습니다.",
+			"actor", subject(p), "container", c.Name(), "item", i.Name()), p)
+		g.emit(p, world.StepFetch, i.Template)
+		return nil
+	}
+
+	if i, ok := item.Find(g.floor[p.Room()], a.object); ok && i.Fixed() {
+		p.Send(josa.Format("{item:은/는} 주울 수 없습니다.", "item", i.Name()))
+		return nil
+	}
+	i, ok := g.takeFromFloor(p.Room(), a.object)
+	if !ok {
+		p.Send("그런 것은 보이지 않습니다: " + a.object)
+		return nil
+	}
+	p.Inventory().Add(i)
+	p.Send(josa.Format("{item:을/를} 주웠습니다.", "item", i.Name()))
+	g.sendRoom(p.Room(), josa.Format("{actor} {item:을/를} 주웠습니다.",
+		"actor", subject(p), "item", i.Name()), p)
+	g.emit(p, world.StepFetch, i.Template)
+	return nil
+})
+
+var _ = register("버려", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("무엇을 버릴까요?")
+		return nil
+	}
+	i, ok := p.Inventory().Remove(a.object)
+	if !ok {
+		p.Send("그런 것은 가지고 있지 않습니다: " + a.object)
+		return nil
+	}
+	g.floor[p.Room()] = append(g.floor[p.Room()], i)
+	p.Send(josa.Format("{item:을/를} 버렸습니다.", "item", i.Name()))
+	g.sendRoom(p.Room(), josa.Format("{actor} {item:을/를} 버렸습니다.",
+		"actor", subject(p), "item", i.Name()), p)
+	return nil
+})
+
+var _ = register("줘", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.to == "" || a.object == "" {
+		p.Send("사용법: <누구>에게 <무엇>을 줘")
+		return nil
+	}
+
+	var target Actor
+	for _, other := range g.playersIn(p.Room()) {
+		if other != p && matches(a.to, other.Name(), nil) {
+			target = other
+			break
+		}
+	}
+	for _, m := range g.mobsIn(p.Room()) {
+		if target == nil && m != p && m.Matches(a.to) {
+			target = m
+		}
+	}
+	if target == nil {
+		p.Send("그런 상대는 여기 없습니다: " + a.to)
+		return nil
+	}
+
+	i, ok := p.Inventory().Remove(a.object)
+	if !ok {
+		p.Send("그런 것은 가지고 있지 않습니다: " + a.object)
+		return nil
+	}
+	target.Inventory().Add(i)
+	p.Send(josa.Format("{target} {item:을/를} 주었습니다.", "target", recipient(target), "item", i.Name()))
+	target.Send(josa.Format("{actor} {item:을/를} 주었습니다.", "actor", subject(p), "item", i.Name()))
+	for _, other := range g.playersIn(p.Room()) {
+		if other != p && other != target {
+			other.Send(josa.Format("{actor} {target} {item:을/를} 주었습니다.",
+				"actor", subject(p), "target", recipient(target), "item", i.Name()))
+		}
+	}
+
+	g.emit(target, world.StepFetch, i.Template)
+	g.itemHook(i, script.OnGive, ref(p), ref(target))
+	if m, ok := target.(*Mob); ok && g.alive(m) {
+		g.mobHook(m, script.OnGive, ref(p), itemRef(i))
+	}
+	return nil
+})
+
+var _ = register("넣어", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.into == "" || a.object == "" {
+		p.Send("사용법: <무엇>을 <어디>에 넣어")
+		return nil
+	}
+	c, ok := g.container(p, a.into)
+	if !ok {
+		p.Send("그런 것은 보이지 않습니다: " + a.into)
+		return nil
+	}
+	i, ok := p.Inventory().Remove(a.object)
+	if !ok {
+		p.Send("그런 것은 가지고 있지 않습니다: " + a.object)
+		return nil
+	}
+	if err := c.Put(i); err != nil {
+		p.Inventory().Add(i)
+		switch err {
+		case item.ErrFull:
+			p.Send(c.Name() + "에 더 이상 넣을 수 없습니다.")
+		case item.ErrSelf:
+			p.Send("자기 자신에 넣을 수는 없습니다.")
+		default:
+			p.Send(c.Name() + "에는 넣을 수 없습니다.")
+		}
+		return nil
+	}
+	p.Send(josa.Format("{item:을/를} {container}에 넣었습니다.", "item", i.Name(), "container", c.Name()))
+	return nil
+})
+
+func equip(p Actor, word string, weapon bool) {
+	word = stripObject(word)
+	if word == "" {
+		if weapon {
+			p.Send("무엇을 들까요?")
+		} else {
+			p.Send("무엇을 입을까요?")
+		}
+		return
+	}
+	i, ok := p.Inventory().Find(word)
+	if !ok {
+		p.Send("그런 것은 가지고 있지 않습니다: " + word)
+		return
+	}
+	if weapon != (i.Slot() == world.SlotWeapon) || i.Slot() == "" {
+		if weapon {
+			p.Send(josa.Format("{item:은/는} 들 수 있는 것이 아닙니다.", "item", i.Name()))
+		} else {
+			p.Send(josa.Format("{item:은/는} 입을 수 있는 것이 아닙니다.", "item", i.Name()))
+		}
+		return
+	}
+
+	if _, err := p.Inventory().Equip(word); err == item.ErrSlotOccupied {
+		p.Send(i.Slot() + "에 이미 다른 것을 착용하고 있습니다.")
+		return
+	}
+	if weapon {
+		p.Send(josa.Format("{item:을/를} 들었습니다.", "item", i.Name()))
+	} else {
+		p.Send(josa.Format("{item:을/를} 입었습니다.", "item", i.Name()))
+	}
+}
+
+var _ = register("입어", func(g *Game, p Actor, args []string) error {
+	equip(p, parseArgs(args).object, false)
+	return nil
+})
+
+var _ = register("들어", func(g *Game, p Actor, args []string) error {
+	equip(p, parseArgs(args).object, true)
+	return nil
+})
+
+var _ = register("벗어", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("무엇을 벗을까요?")
+		return nil
+	}
+	i, ok := p.Inventory().Unequip(a.object)
+	if !ok {
+		p.Send("그런 것은 착용하고 있지 않습니다: " + a.object)
+		return nil
+	}
+	p.Send(josa.Format("{item:을/를} 벗었습니다.", "item", i.Name()))
+	return nil
+})
+
+var _ = register("소지품", func(g *Game, p Actor, args []string) error {
+	equipment := make(map[string]string)
+	for slot, i := range p.Inventory().Equipment {
+		equipment[slot] = i.Name()
+	}
+	p.Send(render.Inventory(item.Names(p.Inventory().Items), equipment, world.Slots))
+	return nil
+})
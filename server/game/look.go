@@ -0,0 +1,142 @@
+package game
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/server/render"
+)
+
+func (g *Game) roomView(p Actor) event.Room {
+	room, ok := g.world.Room(p.Room())
+	if !ok {
+		return event.Room{ID: p.Room()}
+	}
+
+	view := event.Room{
+		ID:          room.ID,
+		Name:        room.Name,
+		Description: room.Description,
+	}
+	for dir := range room.Exits {
+		view.Exits = append(view.Exits, dir)
+	}
+	sort.Strings(view.Exits)
+
+	view.Items = item.Names(g.floor[p.Room()])
+	for _, m := range g.mobsIn(p.Room()) {
+		view.Mobs = append(view.Mobs, m.Name())
+	}
+	for _, other := range g.playersIn(p.Room()) {
+		if other != p {
+			view.Players = append(view.Players, other.Name())
+		}
+	}
+	return view
+}
+
+func (g *Game) look(p Actor) {
+	view := g.roomView(p)
+	p.Send(render.Room(view))
+	if err := p.SendEvent(event.RoomKind, view); err != nil {
+		g.logger.Warn(
+			"event sending failed",
+			"kind", event.RoomKind,
+			"err", err,
+		)
+	}
+}
+
+// target finds something in the room of the player by name or keyword.
+func (g *Game) target(p Actor, word string) (event.Target, bool) {
+	room, ok := g.world.Room(p.Room())
+	if !ok {
+		return event.Target{}, false
+	}
+
+	if i, ok := item.Find(g.floor[p.Room()], word); ok {
+		return itemTarget(i), true
+	}
+	if i, ok := p.Inventory().FindAny(word); ok {
+		return itemTarget(i), true
+	}
+	for _, m := range g.mobsIn(p.Room()) {
+		if m.Matches(word) {
+			return event.Target{Kind: "mob", Name: m.Name(), Description: m.t.Description}, true
+		}
+	}
+	for _, other := range g.playersIn(p.Room()) {
+		if matches(word, other.Name(), nil) {
+			return event.Target{Kind: "player", Name: other.Name()}, true
+		}
+	}
+	if to, ok := room.Exits[word]; ok {
+		if r, ok := g.world.Room(to); ok {
+			desc := josa.Format("{room:으로/로} 이어집니다.", "room", r.Name)
+			return event.Target{Kind: "exit", Name: word, Description: desc}, true
+		}
+	}
+	return event.Target{}, false
+}
+
+func itemTarget(i *item.Item) event.Target {
+	t := event.Target{Kind: "item", Name: i.Name(), Description: i.Description()}
+	if i.IsContainer() {
+		t.Kind = "container"
+		t.Contents = item.Names(i.Contents)
+	}
+	return t
+}
+
+func (g *Game) examine(p Actor, word string) {
+	t, ok := g.target(p, word)
+	if !ok {
+		p.Send("그런 것은 보이지 않습니다: " + word)
+		return
+	}
+
+	p.Send(render.Target(t))
+	if err := p.SendEvent(event.TargetKind, t); err != nil {
+		g.logger.Warn(
+			"event sending failed",
+			"kind", event.TargetKind,
+			"err", err,
+		)
+	}
+}
+
+func matches(word, name string, keywords []string) bool {
+	if word == "" {
+		return false
+	}
+	if word == name || strings.HasPrefix(name, word) {
+		return true
+	}
+	for _, k := range keywords {
+		if word == k {
+			return true
+		}
+	}
+	return false
+}
+
+var _ = register("봐", func(g *Game, p Actor, args []string) error {
+	if len(args) > 0 {
+		g.examine(p, strings.Join(args, " "))
+		return nil
+	}
+	g.look(p)
+	return nil
+})
+
+var _ = register("살펴", func(g *Game, p Actor, args []string) error {
+	if len(args) == 0 {
+		p.Send("무엇을 살펴볼까요?")
+		return nil
+	}
+	g.examine(p, strings.Join(args, " "))
+	return nil
+})
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"context"
+	"time"
+
+	"github.com/zrma/mud/server/scheduler"
+)
+
+const (
+	defaultTickRate = 100 * time.Millisecond
+	combatInterval  = 2 * time.Second
+	regenInterval   = 3 * time.Second
+)
+
+// Run drives everything that happens with time, one tick per tick rate of the game clock, until
+// ctx is done.
+func (g *Game) Run(ctx context.Context) {
+	ticker := g.clock.NewTicker(g.tickRate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C():
+			g.Advance(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Advance runs everything that came due up to now. Run calls it on every tick, and calling it
+// directly steps the game deterministically.
+func (g *Game) Advance(now time.Time) {
+	g.Lock()
+	defer g.Unlock()
+
+	g.scheduler.Advance(now)
+}
+
+// After runs fn once after d of game time. Like commands, fn runs with the game lock held and
+// must only be called from game code.
+func (g *Game) After(d time.Duration, fn func()) scheduler.Timer {
+	return g.scheduler.After(d, fn)
+}
+
+// Every runs fn every d of game time, see After.
+func (g *Game) Every(d time.Duration, fn func()) (scheduler.Timer, error) {
+	return g.scheduler.Every(d, fn)
+}
+
+func (g *Game) Cancel(t scheduler.Timer) bool {
+	return g.scheduler.Cancel(t)
+}
+
+// startSystems schedules the parts of the game that run on their own.
+func (g *Game) startSystems() error {
+	for _, system := range []struct {
+		interval time.Duration
+		run      func()
+	}{
+		{combatInterval, g.combatRound},
+		{regenInterval, g.regenerate},
+		{aiInterval, g.runAI},
+		{scriptTickInterval, g.scriptTick},
+		{g.saveEvery, g.saveAll},
+		{weatherInterval, g.changeWeather},
+	} {
+		if _, err := g.Every(system.interval, system.run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Game) regenerate() {
+	for _, p := range g.sortedPlayers() {
+		if g.combat.Fighting(p) {
+			continue
+		}
+		if p.Character().Regenerate() {
+			g.sendStats(p)
+		}
+	}
+	for _, m := range g.mobs {
+		if !g.combat.Fighting(m) {
+			m.Character().Regenerate()
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/pborman/uuid"
+
+	"github.com/zrma/mud/character"
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/server/npc"
+	"github.com/zrma/mud/world"
+)
+
+// Mob is a living instance of a mob template.
+type Mob struct {
+	ID       string
+	Template string
+	Home     string
+
+	room      string
+	inventory *item.Inventory
+	character *character.Character
+	behaviors []npc.Behavior
+	t         *world.MobTemplate
+}
+
+func newMob(t *world.MobTemplate, room string) *Mob {
+	c := character.New(t.Name)
+	if t.Level > 1 {
+		c.Level = t.Level
+		c.Recalculate()
+		c.HP, c.MP, c.Stamina = c.MaxHP, c.MaxMP, c.MaxStamina
+	}
+	return &Mob{
+		ID:        uuid.New(),
+		Template:  t.ID,
+		Home:      room,
+		room:      room,
+		inventory: item.NewInventory(),
+		character: c,
+		behaviors: npc.New(t),
+		t:         t,
+	}
+}
+
+func (m *Mob) Name() string {
+	return m.t.Name
+}
+
+func (m *Mob) Room() string {
+	return m.room
+}
+
+func (m *Mob) setRoom(room string) {
+	m.room = room
+}
+
+func (m *Mob) Inventory() *item.Inventory {
+	return m.inventory
+}
+
+// Send drops the message, mobs react to what happens through their behaviors instead.
+func (m *Mob) Send(msg string) {}
+
+func (m *Mob) SendEvent(kind event.Kind, v interface{}) error {
+	return nil
+}
+
+func (m *Mob) Character() *character.Character {
+	return m.character
+}
+
+func (m *Mob) Damage() int {
+	return m.t.Damage
+}
+
+func (m *Mob) Armor() int {
+	return m.t.Armor
+}
+
+func (m *Mob) Matches(word string) bool {
+	return matches(word, m.t.Name, m.t.Keywords)
+}
+
+// spawnMobs populates the rooms of the area files that were never populated before.
+func (g *Game) spawnMobs() {
+	if g.spawned == nil {
+		g.spawned = make(map[string]bool)
+	}
+	for _, id := range sortedRoomIDs(g.world) {
+		if g.spawned[id] {
+			continue
+		}
+		g.spawned[id] = true
+		for _, t := range g.world.Rooms[id].Mobs {
+			g.mobs = append(g.mobs, newMob(g.world.Mobs[t], id))
+		}
+	}
+}
+
+// bindMobs follows a reload. Mobs whose template or room is gone disappear.
+func (g *Game) bindMobs() {
+	mobs := g.mobs[:0]
+	for _, m := range g.mobs {
+		t, ok := g.world.Mobs[m.Template]
+		if _, exists := g.world.Room(m.Room()); !ok || !exists {
+			g.combat.Disengage(m)
+			continue
+		}
+		m.t = t
+		m.behaviors = npc.New(t)
+		mobs = append(mobs, m)
+	}
+	g.mobs = mobs
+	for id := range g.spawned {
+		if _, ok := g.world.Room(id); !ok {
+			delete(g.spawned, id)
+		}
+	}
+	g.spawnMobs()
+}
+
+func (g *Game) mobsIn(room string) []*Mob {
+	var mobs []*Mob
+	for _, m := range g.mobs {
+		if m.Room() == room {
+			mobs = append(mobs, m)
+		}
+	}
+	return mobs
+}
+
+func (g *Game) removeMob(m *Mob) {
+	for i, other := range g.mobs {
+		if other == m {
+			g.mobs = append(g.mobs[:i:i], g.mobs[i+1:]...)
+			break
+		}
+	}
+	g.combat.Disengage(m)
+
+	template, room := m.Template, m.Home
+	g.After(npc.RespawnDelay(m.t), func() {
+		t, ok := g.world.Mobs[template]
+		if _, exists := g.world.Room(room); !ok || !exists {
+			return
+		}
+		m := newMob(t, room)
+		g.mobs = append(g.mobs, m)
+		g.sendRoom(room, josa.Format("{mob:이/가} 나타났습니다.", "mob", m.Name()), nil)
+	})
+}
+
+func sortedRoomIDs(w *world.World) []string {
+	ids := make([]string, 0, len(w.Rooms))
+	for id := range w.Rooms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
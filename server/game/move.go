@@ -0,0 +1,58 @@
+package game
+
+import "strings"
+
+// move takes the player through an exit of the current room and shows the new room.
+func (g *Game) move(p Actor, dir string) bool {
+	room, ok := g.world.Room(p.Room())
+	if !ok {
+		return false
+	}
+	to, ok := room.Exits[dir]
+	if !ok {
+		return false
+	}
+	if _, ok := g.world.Room(to); !ok {
+		return false
+	}
+	if g.combat.Fighting(p) {
+		p.Send("싸우는 중에는 움직일 수 없습니다. 도망치세요!")
+		return true
+	}
+
+	g.sendRoom(p.Room(), subject(p)+" 떠났습니다.", p)
+	p.setRoom(to)
+	g.sendRoom(p.Room(), subject(p)+" 왔습니다.", p)
+	g.look(p)
+	g.enter(p)
+	return true
+}
+
+func (g *Game) playersIn(room string) []*Player {
+	var players []*Player
+	for _, p := range g.sortedPlayers() {
+		if p.Room() == room {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+func (g *Game) sendRoom(room, msg string, except Actor) {
+	for _, p := range g.playersIn(room) {
+		if p != except {
+			p.Send(msg)
+		}
+	}
+}
+
+var _ = register("가", func(g *Game, p Actor, args []string) error {
+	if len(args) == 0 {
+		p.Send("어디로 갈까요?")
+		return nil
+	}
+	if !g.move(p, strings.Join(args, " ")) {
+		p.Send("그쪽으로는 갈 수 없습니다.")
+	}
+	return nil
+})
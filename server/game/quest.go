@@ -0,0 +1,192 @@
+package game
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/quest"
+	"github.com/zrma/mud/world"
+)
+
+// emit tells the systems following the world that an actor did something: killed a mob,
+// obtained an item, talked to a mob or reached a room. Only players have quests for now.
+func (g *Game) emit(a Actor, kind, target string) {
+	p, ok := a.(*Player)
+	if !ok || p.character.Quests == nil {
+		return
+	}
+	e := quest.Event{Kind: kind, Target: target}
+	if kind == world.StepFetch {
+		e.Have = p.Inventory().Count(target)
+	}
+	for _, u := range p.character.Quests.Apply(g.world.Quests, e) {
+		switch {
+		case u.Completed:
+			g.completeQuest(p, u.Quest)
+		case u.StepDone:
+			p.Send("{y}[퀘스트] " + u.Quest.Name + ": " + u.Step.Description + " 완료{x}")
+			g.checkHeld(p, u.Quest)
+		default:
+			p.Send("{y}[퀘스트] " + u.Quest.Name + ": " + u.Step.Description +
+				" (" + strconv.Itoa(u.Count) + "/" + strconv.Itoa(u.Step.Needed()) + "){x}")
+		}
+	}
+}
+
+// checkHeld counts what the player already holds when the current step of a quest is a fetch
+// step, which otherwise would only count with the next item the player obtains.
+func (g *Game) checkHeld(p *Player, q *world.Quest) {
+	pr, ok := p.character.Quests.Active[q.ID]
+	if !ok || pr.Step >= len(q.Steps) {
+		return
+	}
+	if s := q.Steps[pr.Step]; s.Kind == world.StepFetch {
+		g.emit(p, world.StepFetch, s.Target)
+	}
+}
+
+func (g *Game) completeQuest(p *Player, q *world.Quest) {
+	p.Send("{Y}퀘스트를 완료했습니다: " + q.Name + "{x}")
+
+	r := q.Reward
+	c := p.Character()
+	if r.Gold > 0 {
+		c.Gold += r.Gold
+		p.Send(strconv.Itoa(r.Gold) + " 골드를 받았습니다.")
+	}
+	for _, id := range r.Items {
+		if t, ok := g.world.Items[id]; ok {
+			i := item.New(t)
+			p.Inventory().Add(i)
+			p.Send(josa.Format("{item:을/를} 받았습니다.", "item", i.Name()))
+		}
+	}
+	if r.Exp > 0 {
+		p.Send(josa.Format("경험치 {exp:을/를} 얻었습니다.", "exp", strconv.Itoa(r.Exp)))
+		if levels := c.GainExp(r.Exp); levels > 0 {
+			p.Send("{Y}레벨이 올랐습니다! 이제 레벨 " + strconv.Itoa(c.Level) + "입니다.{x}")
+		}
+	}
+	g.sendStats(p)
+}
+
+// offered lists the quests the givers in the room of the player hand out to them.
+func (g *Game) offered(p *Player) []*world.Quest {
+	givers := make(map[string]bool)
+	for _, m := range g.mobsIn(p.Room()) {
+		givers[m.Template] = true
+	}
+
+	var quests []*world.Quest
+	for _, q := range g.world.Quests {
+		if q.Giver != "" && !givers[q.Giver] {
+			continue
+		}
+		if p.character.Quests.CanStart(q, p.Character().Level) == nil {
+			quests = append(quests, q)
+		}
+	}
+	sort.Slice(quests, func(i, j int) bool {
+		return quests[i].ID < quests[j].ID
+	})
+	return quests
+}
+
+func findQuest(quests []*world.Quest, word string) (*world.Quest, bool) {
+	for _, q := range quests {
+		if q.ID == word || matches(word, q.Name, nil) {
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+func questProgress(q *world.Quest, pr *quest.Progress) string {
+	if pr.Step >= len(q.Steps) {
+		return q.Name
+	}
+	s := q.Steps[pr.Step]
+	return q.Name + " - " + s.Description + " (" + strconv.Itoa(pr.Count) + "/" + strconv.Itoa(s.Needed()) + ")"
+}
+
+var _ = register("퀘스트", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	log := player.character.Quests
+
+	lines := []string{"{W}진행 중인 퀘스트{x}"}
+	ids := log.ActiveIDs()
+	for _, id := range ids {
+		if q, ok := g.world.Quests[id]; ok {
+			lines = append(lines, "  "+questProgress(q, log.Active[id]))
+		}
+	}
+	if len(ids) == 0 {
+		lines = append(lines, "  없음")
+	}
+	if offered := g.offered(player); len(offered) > 0 {
+		lines = append(lines, "{W}받을 수 있는 퀘스트{x}")
+		for _, q := range offered {
+			lines = append(lines, "  "+q.Name+" - "+q.Description)
+		}
+		lines = append(lines, "'<퀘스트> 수락'으로 받을 수 있습니다.")
+	}
+	p.Send(strings.Join(lines, "\n"))
+	return nil
+})
+
+var _ = register("수락", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("어떤 퀘스트를 받을까요?")
+		return nil
+	}
+	q, ok := findQuest(g.offered(player), a.object)
+	if !ok {
+		p.Send("받을 수 있는 퀘스트가 아닙니다: " + a.object)
+		return nil
+	}
+	if err := player.character.Quests.Start(q, p.Character().Level); err != nil {
+		return err
+	}
+	p.Send("{Y}퀘스트를 받았습니다: " + q.Name + "{x}\n" + q.Description)
+	p.Send("  " + questProgress(q, player.character.Quests.Active[q.ID]))
+	g.checkHeld(player, q)
+	return nil
+})
+
+var _ = register("포기", func(g *Game, p Actor, args []string) error {
+	player, ok := p.(*Player)
+	if !ok {
+		return nil
+	}
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("어떤 퀘스트를 포기할까요?")
+		return nil
+	}
+
+	var active []*world.Quest
+	for _, id := range player.character.Quests.ActiveIDs() {
+		if q, ok := g.world.Quests[id]; ok {
+			active = append(active, q)
+		}
+	}
+	q, ok := findQuest(active, a.object)
+	if !ok {
+		p.Send("진행 중인 퀘스트가 아닙니다: " + a.object)
+		return nil
+	}
+	player.character.Quests.Abandon(q.ID)
+	p.Send("퀘스트를 포기했습니다: " + q.Name)
+	return nil
+})
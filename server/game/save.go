@@ -0,0 +1,213 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zrma/mud/chat"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/quest"
+	"github.com/zrma/mud/role"
+	"github.com/zrma/mud/server/session"
+	"github.com/zrma/mud/store"
+)
+
+const defaultSaveInterval = 5 * time.Minute
+
+var (
+	ErrPassword = errors.New("wrong password")
+	ErrAccount  = errors.New("account can't be loaded")
+)
+
+// login checks the password of an account, which is created when it is new, and returns it. An
+// account without a password takes the first one it is given, unless its role is above player:
+// a name alone must not be enough to claim it, so it only gets one with "mud password". An
+// account that can't be loaded is refused with ErrAccount. Without a store there are no accounts
+// and login returns nil.
+func (g *Game) login(name, password string) (*store.Account, error) {
+	if g.store == nil {
+		return nil, nil
+	}
+
+	now := g.clock.Now()
+	a, err := g.store.LoadAccount(name)
+	switch err {
+	case nil:
+	case store.ErrNotFound:
+		a = &store.Account{Name: name, Created: now}
+	default:
+		g.storeFailed("account", name, err)
+		return nil, ErrAccount
+	}
+
+	switch {
+	case a.Password != "":
+		if !store.CheckPassword(a.Password, password) {
+			return nil, ErrPassword
+		}
+	case password != "" && a.Role == role.Player:
+		hash, err := store.HashPassword(password)
+		if err != nil {
+			g.logger.Err(
+				"password hashing failed",
+				"name", name,
+				"err", err,
+			)
+			break
+		}
+		a.Password = hash
+	}
+	a.LastLogin = now
+	if err := g.store.SaveAccount(a); err != nil {
+		g.storeFailed("account", name, err)
+	}
+	return a, nil
+}
+
+// loadPlayer restores a saved character, or creates a new one. A room that no longer exists is
+// replaced by the start room and items of vanished templates are dropped. The role comes from
+// the account a, and only when it has a password that login checked.
+func (g *Game) loadPlayer(name string, a *store.Account, sess *session.Session) *Player {
+	p := newPlayer(name, g.world.Start, sess)
+	if g.store == nil {
+		return p
+	}
+	if a != nil && a.Password != "" {
+		p.role = a.Role
+	}
+	if a != nil && a.MutedUntil != nil {
+		p.mutedUntil = *a.MutedUntil
+	}
+
+	rec, err := g.store.LoadPlayer(name)
+	if err != nil {
+		if err != store.ErrNotFound {
+			g.storeFailed("player", name, err)
+		}
+		return p
+	}
+	if _, ok := g.world.Room(rec.Room); ok {
+		p.room = rec.Room
+	}
+	if rec.Character != nil {
+		p.character = rec.Character
+		p.character.Recalculate()
+		if p.character.Quests == nil {
+			p.character.Quests = quest.NewLog()
+		}
+		if p.character.Chat == nil {
+			p.character.Chat = chat.NewSettings()
+		}
+	}
+	if rec.Inventory != nil {
+		rec.Inventory.Items = g.known(rec.Inventory.Items)
+		for slot, i := range rec.Inventory.Equipment {
+			if len(g.known([]*item.Item{i})) == 0 {
+				delete(rec.Inventory.Equipment, slot)
+			}
+		}
+		rec.Inventory.Bind(g.world)
+		p.inventory = rec.Inventory
+	}
+	return p
+}
+
+// known drops items whose template is gone, together with their contents.
+func (g *Game) known(items []*item.Item) []*item.Item {
+	result := items[:0]
+	for _, i := range items {
+		if _, ok := g.world.Items[i.Template]; !ok {
+			continue
+		}
+		i.Contents = g.known(i.Contents)
+		result = append(result, i)
+	}
+	return result
+}
+
+func (g *Game) savePlayer(p *Player) {
+	if g.store == nil {
+		return
+	}
+	if err := g.store.SavePlayer(&store.Player{
+		Name:      p.Name(),
+		Room:      p.Room(),
+		Character: p.Character(),
+		Inventory: p.Inventory(),
+		Saved:     g.clock.Now(),
+	}); err != nil {
+		g.storeFailed("player", p.Name(), err)
+	}
+}
+
+// restoreWorld brings back the items that were lying in the rooms and the bans. Rooms without
+// saved state are populated by spawnItems as usual.
+func (g *Game) restoreWorld() {
+	if g.store == nil {
+		return
+	}
+	w, err := g.store.LoadWorld()
+	if err != nil {
+		if err != store.ErrNotFound {
+			g.storeFailed("world", "", err)
+		}
+		return
+	}
+	if w.Bans != nil {
+		for name, b := range w.Bans.Accounts {
+			g.bans.Accounts[name] = b
+		}
+		for address, b := range w.Bans.Addresses {
+			g.bans.Addresses[address] = b
+		}
+	}
+	for id, items := range w.Floor {
+		if _, ok := g.world.Room(id); !ok {
+			continue
+		}
+		items = g.known(items)
+		for _, i := range items {
+			i.Bind(g.world)
+		}
+		g.floor[id] = items
+	}
+}
+
+func (g *Game) saveWorld() {
+	if g.store == nil {
+		return
+	}
+	if err := g.store.SaveWorld(&store.World{
+		Floor: g.floor,
+		Bans:  g.bans,
+		Saved: g.clock.Now(),
+	}); err != nil {
+		g.storeFailed("world", "", err)
+	}
+}
+
+func (g *Game) saveAll() {
+	for _, p := range g.sortedPlayers() {
+		g.savePlayer(p)
+	}
+	g.saveWorld()
+}
+
+// Close saves everything one last time. Later changes, like players leaving while the server
+// shuts down, are not saved anymore.
+func (g *Game) Close() {
+	g.Lock()
+	defer g.Unlock()
+
+	g.saveAll()
+	g.store = nil
+}
+
+func (g *Game) storeFailed(kind, name string, err error) {
+	g.logger.Err(
+		"store failed",
+		"kind", kind,
+		"name", name,
+		"err", err,
+	)
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/zrma/mud/chat"
+	"github.com/zrma/mud/markup"
+)
+
+var _ = register("말", func(g *Game, p Actor, args []string) error {
+	if len(args) == 0 {
+		p.Send("무슨 말을 할까요?")
+		return nil
+	}
+	msg := strings.Join(args, " ")
+	if player, ok := p.(*Player); ok && g.speak(player, msg) != nil {
+		return nil
+	}
+	p.Send("{C}당신{x}: " + markup.Escape(msg))
+	for _, other := range g.playersIn(p.Room()) {
+		if other != p && !other.character.Chat.Ignores(p.Name()) {
+			other.Send("{C}" + markup.Escape(p.Name()) + "{x}: " + markup.Escape(msg))
+		}
+	}
+
+	g.remember(g.roomHistory, p.Room(), chat.Entry{Sender: p.Name(), Text: msg})
+
+	if player, ok := p.(*Player); ok {
+		g.hear(player, msg)
+	}
+	return nil
+})
@@ -0,0 +1,22 @@
+package game
+
+import (
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/server/render"
+)
+
+// sendStats pushes the numbers of the character so that clients can update their status bars.
+func (g *Game) sendStats(p Actor) {
+	if err := p.SendEvent(event.StatsKind, p.Character().Stats()); err != nil {
+		g.logger.Warn(
+			"event sending failed",
+			"kind", event.StatsKind,
+			"err", err,
+		)
+	}
+}
+
+var _ = register("점수", func(g *Game, p Actor, args []string) error {
+	p.Send(render.Score(p.Character().Stats()))
+	return nil
+})
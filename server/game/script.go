@@ -0,0 +1,263 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/server/script"
+	"github.com/zrma/mud/world"
+)
+
+const scriptTickInterval = time.Second
+
+var (
+	errNoSuchActor = errors.New("no such player or mob")
+	errNoSuchRoom  = errors.New("no such room")
+	errNoSuchStat  = errors.New("unknown stat")
+)
+
+// loadScripts starts a fresh script engine for the current world. A script that fails to run
+// its top level is left out and logged, the rest of the world works without it.
+func (g *Game) loadScripts() {
+	if g.scripts != nil {
+		g.scripts.Close()
+	}
+	g.scripts = script.New(scriptAPI{g}, script.DefaultLimits, g.scriptFailed)
+
+	names := make([]string, 0, len(g.world.Scripts))
+	for name := range g.world.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := g.scripts.Load(name, g.world.Scripts[name]); err != nil {
+			g.scriptFailed(name, err)
+		}
+	}
+}
+
+func (g *Game) scriptFailed(name string, err error) {
+	g.logger.Warn(
+		"script failed",
+		"script", name,
+		"err", err,
+	)
+}
+
+func (g *Game) callScript(name, hook string, args ...interface{}) {
+	if name == "" {
+		return
+	}
+	if err := g.scripts.Call(name, hook, args...); err != nil {
+		g.scriptFailed(name, err)
+	}
+}
+
+func (g *Game) roomHook(room, hook string, args ...interface{}) {
+	r, ok := g.world.Room(room)
+	if !ok || r.Script == "" {
+		return
+	}
+	self := script.Ref{Kind: script.KindRoom, ID: r.ID, Name: r.Name}
+	g.callScript(r.Script, hook, append([]interface{}{self}, args...)...)
+}
+
+func (g *Game) mobHook(m *Mob, hook string, args ...interface{}) {
+	g.callScript(m.t.Script, hook, append([]interface{}{ref(m)}, args...)...)
+}
+
+// mobHooks calls a hook of every mob that is still alive when its turn comes.
+func (g *Game) mobHooks(mobs []*Mob, hook string, args ...interface{}) {
+	for _, m := range append([]*Mob(nil), mobs...) {
+		if g.alive(m) {
+			g.mobHook(m, hook, args...)
+		}
+	}
+}
+
+func (g *Game) itemHook(i *item.Item, hook string, args ...interface{}) {
+	t, ok := g.world.Items[i.Template]
+	if !ok {
+		return
+	}
+	g.callScript(t.Script, hook, append([]interface{}{itemRef(i)}, args...)...)
+}
+
+// enter runs what happens when an actor shows up in a room: the on_enter of the room, and for
+// players the behaviors and scripts of the mobs there.
+func (g *Game) enter(a Actor) {
+	g.emit(a, world.StepReach, a.Room())
+	g.roomHook(a.Room(), script.OnEnter, ref(a))
+	if p, ok := a.(*Player); ok {
+		g.arrive(p)
+	}
+}
+
+func (g *Game) scriptTick() {
+	for _, id := range sortedRoomIDs(g.world) {
+		g.roomHook(id, script.OnTick)
+	}
+	g.mobHooks(g.mobs, script.OnTick)
+}
+
+func ref(a Actor) script.Ref {
+	switch a := a.(type) {
+	case *Mob:
+		return script.Ref{Kind: script.KindMob, ID: a.ID, Name: a.Name()}
+	}
+	return script.Ref{Kind: script.KindPlayer, ID: a.Name(), Name: a.Name()}
+}
+
+func itemRef(i *item.Item) script.Ref {
+	return script.Ref{Kind: script.KindItem, ID: i.ID, Name: i.Name()}
+}
+
+// scriptAPI is the game as scripts see it. Players are referred to by name, mobs and items by
+// their instance id.
+type scriptAPI struct {
+	g *Game
+}
+
+func (api scriptAPI) actor(r script.Ref) (Actor, error) {
+	switch r.Kind {
+	case script.KindPlayer:
+		for _, p := range api.g.sortedPlayers() {
+			if p.Name() == r.ID {
+				return p, nil
+			}
+		}
+	case script.KindMob:
+		for _, m := range api.g.mobs {
+			if m.ID == r.ID {
+				return m, nil
+			}
+		}
+	}
+	return nil, errNoSuchActor
+}
+
+func (api scriptAPI) Send(to script.Ref, msg string) error {
+	a, err := api.actor(to)
+	if err != nil {
+		return err
+	}
+	a.Send(msg)
+	return nil
+}
+
+func (api scriptAPI) SendRoom(room, msg string) error {
+	if _, ok := api.g.world.Room(room); !ok {
+		return errNoSuchRoom
+	}
+	api.g.sendRoom(room, msg, nil)
+	return nil
+}
+
+func (api scriptAPI) Move(who script.Ref, room string) error {
+	a, err := api.actor(who)
+	if err != nil {
+		return err
+	}
+	if _, ok := api.g.world.Room(room); !ok {
+		return errNoSuchRoom
+	}
+	g := api.g
+	g.combat.Disengage(a)
+	g.sendRoom(a.Room(), subject(a)+" 사라졌습니다.", a)
+	a.setRoom(room)
+	g.sendRoom(a.Room(), subject(a)+" 나타났습니다.", a)
+	g.look(a)
+	g.enter(a)
+	return nil
+}
+
+func (api scriptAPI) Stat(who script.Ref, name string) (int, error) {
+	a, err := api.actor(who)
+	if err != nil {
+		return 0, err
+	}
+	c := a.Character()
+	switch name {
+	case "hp":
+		return c.HP, nil
+	case "mp":
+		return c.MP, nil
+	case "stamina":
+		return c.Stamina, nil
+	case "exp":
+		return c.Exp, nil
+	case "gold":
+		return c.Gold, nil
+	case "level":
+		return c.Level, nil
+	}
+	return 0, errNoSuchStat
+}
+
+// SetStat keeps the pools between zero and their maximum. An actor whose hp drops to zero dies.
+func (api scriptAPI) SetStat(who script.Ref, name string, value int) error {
+	a, err := api.actor(who)
+	if err != nil {
+		return err
+	}
+	c := a.Character()
+	dead := c.Dead()
+	switch name {
+	case "hp":
+		c.HP = clamp(value, 0, c.MaxHP)
+	case "mp":
+		c.MP = clamp(value, 0, c.MaxMP)
+	case "stamina":
+		c.Stamina = clamp(value, 0, c.MaxStamina)
+	case "exp":
+		c.Exp = clamp(value, 0, value)
+	case "gold":
+		c.Gold = clamp(value, 0, value)
+	default:
+		return errNoSuchStat
+	}
+
+	if p, ok := a.(*Player); ok {
+		api.g.sendStats(p)
+	}
+	if c.Dead() && !dead {
+		api.g.kill(a, nil)
+	}
+	return nil
+}
+
+func (api scriptAPI) Execute(who script.Ref, line string) error {
+	a, err := api.actor(who)
+	if err != nil {
+		return err
+	}
+	if _, ok := a.(*Mob); !ok {
+		return fmt.Errorf("only mobs can be made to execute commands, not %s", who.Kind)
+	}
+	return api.g.execute(a, line)
+}
+
+func (api scriptAPI) Players(room string) []script.Ref {
+	var refs []script.Ref
+	for _, p := range api.g.playersIn(room) {
+		refs = append(refs, ref(p))
+	}
+	return refs
+}
+
+func (api scriptAPI) After(d time.Duration, f func()) {
+	api.g.After(d, f)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/world"
+)
+
+// shopkeeper finds a mob in the room of the player that is ready to trade.
+func (g *Game) shopkeeper(p Actor) (*Mob, bool) {
+	for _, m := range g.mobsIn(p.Room()) {
+		if m != p && m.t.Has(world.BehaviorShopkeeper) && !g.combat.Fighting(m) {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+func (g *Game) stock(m *Mob) []*world.ItemTemplate {
+	var stock []*world.ItemTemplate
+	for _, id := range m.t.Shop {
+		if t, ok := g.world.Items[id]; ok {
+			stock = append(stock, t)
+		}
+	}
+	return stock
+}
+
+var _ = register("목록", func(g *Game, p Actor, args []string) error {
+	m, ok := g.shopkeeper(p)
+	if !ok {
+		p.Send("여기에는 물건을 파는 사람이 없습니다.")
+		return nil
+	}
+	p.Send("{W}" + m.Name() + "의 물건{x}")
+	for _, t := range g.stock(m) {
+		p.Send(fmt.Sprintf("  %s  {Y}%d{x} 골드", markup.Escape(t.Name), t.Value))
+	}
+	return nil
+})
+
+var _ = register("사", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("무엇을 살까요?")
+		return nil
+	}
+	m, ok := g.shopkeeper(p)
+	if !ok {
+		p.Send("여기에는 물건을 파는 사람이 없습니다.")
+		return nil
+	}
+
+	for _, t := range g.stock(m) {
+		if !matches(a.object, t.Name, t.Keywords) {
+			continue
+		}
+		c := p.Character()
+		if c.Gold < t.Value {
+			p.Send("골드가 모자랍니다. " + t.Name + "의 값은 " + strconv.Itoa(t.Value) + " 골드입니다.")
+			return nil
+		}
+		c.Gold -= t.Value
+		i := item.New(t)
+		p.Inventory().Add(i)
+		p.Send(josa.Format("{mob}에게서 {item:을/를} {price} 골드에 샀습니다.",
+			"mob", m.Name(), "item", i.Name(), "price", strconv.Itoa(t.Value)))
+		g.sendRoom(p.Room(), josa.Format("{actor} {item:을/를} 샀습니다.",
+			"actor", subject(p), "item", i.Name()), p)
+		g.sendStats(p)
+		g.emit(p, world.StepFetch, i.Template)
+		return nil
+	}
+	p.Send(josa.Format("{mob:은/는} 그런 것을 팔지 않습니다: ", "mob", m.Name()) + a.object)
+	return nil
+})
+
+var _ = register("팔아", func(g *Game, p Actor, args []string) error {
+	a := parseArgs(args)
+	if a.object == "" {
+		p.Send("무엇을 팔까요?")
+		return nil
+	}
+	m, ok := g.shopkeeper(p)
+	if !ok {
+		p.Send("여기에는 물건을 사는 사람이 없습니다.")
+		return nil
+	}
+
+	i, ok := p.Inventory().Find(a.object)
+	if !ok {
+		p.Send("그런 것은 가지고 있지 않습니다: " + a.object)
+		return nil
+	}
+	price := i.Value() / 2
+	if price == 0 || len(i.Contents) > 0 {
+		p.Send(josa.Format("{mob:은/는} {item:을/를} 사지 않습니다.", "mob", m.Name(), "item", i.Name()))
+		return nil
+	}
+	p.Inventory().Remove(a.object)
+	p.Character().Gold += price
+	p.Send(josa.Format("{mob}에게 {item:을/를} {price} 골드에 팔았습니다.",
+		"mob", m.Name(), "item", i.Name(), "price", strconv.Itoa(price)))
+	g.sendRoom(p.Room(), josa.Format("{actor} {item:을/를} 팔았습니다.",
+		"actor", subject(p), "item", i.Name()), p)
+	g.sendStats(p)
+	return nil
+})
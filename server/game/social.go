@@ -0,0 +1,101 @@
+package game
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zrma/mud/josa"
+	"github.com/zrma/mud/markup"
+	"github.com/zrma/mud/world"
+)
+
+// fill replaces the names in a social message, with the particles that fit them.
+func fill(text string, actor, target Actor) string {
+	kv := []string{"actor", markup.Escape(actor.Name())}
+	if target != nil {
+		kv = append(kv, "target", markup.Escape(target.Name()))
+	}
+	return josa.Format(text, kv...)
+}
+
+// social runs an emote of the world. The target is a player or mob in the room, named with or
+// without a particle: "철수에게 인사", "철수 웃어".
+func (g *Game) social(p Actor, s *world.Social, args []string) {
+	var target Actor
+	if len(args) > 0 {
+		a := parseArgs(args)
+		name := a.to
+		if name == "" {
+			name = a.object
+		}
+		var ok bool
+		if target, ok = g.actorIn(p.Room(), name); !ok {
+			p.Send("그런 상대는 여기 없습니다: " + markup.Escape(name))
+			return
+		}
+		if target == p {
+			target = nil
+		}
+	}
+
+	if target == nil {
+		p.Send(fill(s.Alone.Actor, p, nil))
+		g.sendRoom(p.Room(), fill(s.Alone.Room, p, nil), p)
+		return
+	}
+	if s.Targeted == nil {
+		p.Send(josa.Attach(s.Word, "은/는") + " 혼자서만 할 수 있습니다.")
+		return
+	}
+	p.Send(fill(s.Targeted.Actor, p, target))
+	target.Send(fill(s.Targeted.Target, p, target))
+	for _, other := range g.playersIn(p.Room()) {
+		if other != p && other != target {
+			other.Send(fill(s.Targeted.Room, p, target))
+		}
+	}
+}
+
+// actorIn finds a player or mob in a room by name, players first.
+func (g *Game) actorIn(room, word string) (Actor, bool) {
+	for _, p := range g.playersIn(room) {
+		if matches(word, p.Name(), nil) {
+			return p, true
+		}
+	}
+	for _, m := range g.mobsIn(room) {
+		if m.Matches(word) {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// 감정 is a free form emote: "머리를 긁적입니다 감정" shows "철수가 머리를 긁적입니다".
+var _ = register("감정", func(g *Game, p Actor, args []string) error {
+	if len(args) == 0 {
+		p.Send("어떤 감정을 표현할까요? 예: 머리를 긁적입니다 감정")
+		return nil
+	}
+	if player, ok := p.(*Player); ok && g.speak(player, strings.Join(args, " ")) != nil {
+		return nil
+	}
+	msg := markup.Escape(josa.Attach(p.Name(), "이/가")) + " " + markup.Escape(strings.Join(args, " "))
+	p.Send(msg)
+	g.sendRoom(p.Room(), msg, p)
+	return nil
+})
+
+var _ = register("소셜", func(g *Game, p Actor, args []string) error {
+	words := make([]string, 0, len(g.world.Socials))
+	for word := range g.world.Socials {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	if len(words) == 0 {
+		p.Send("소셜이 없습니다.")
+		return nil
+	}
+	p.Send("{W}소셜{x}\n  " + strings.Join(words, " ") + "\n'<누구>에게 <소셜>'처럼 상대를 정할 수 있습니다.")
+	return nil
+})
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/zrma/mud/server/limit"
+)
+
+var ErrMuted = errors.New("muted for a while")
+
+// speak tells whether a player may say msg, and tells the player why not. Saying the same thing
+// more often than the limits allow mutes the player for a while.
+func (g *Game) speak(p *Player, msg string) error {
+	now := g.clock.Now()
+	if now.Before(p.mutedUntil) {
+		p.Send("지금은 말할 수 없습니다. " + duration(p.mutedUntil.Sub(now)) + " 뒤에 다시 해 보세요.")
+		return ErrMuted
+	}
+	if g.limits.Repeats <= 0 || g.limits.MuteFor <= 0 {
+		return nil
+	}
+	if p.repeats.Add(msg, now, g.limits.RepeatWindow) > g.limits.Repeats {
+		g.mute(p, g.limits.MuteFor)
+		p.Send("같은 말을 너무 자주 해서 " + duration(g.limits.MuteFor) + " 동안 말할 수 없습니다.")
+		return ErrMuted
+	}
+	return nil
+}
+
+func (g *Game) mute(p *Player, d time.Duration) {
+	p.mutedUntil = g.clock.Now().Add(d)
+	p.repeats.Reset()
+	g.saveMute(p)
+	limit.Metrics.Add(limit.MetricMute, 1)
+	g.logger.Info(
+		"player muted",
+		"name", p.Name(),
+		"for", d,
+	)
+}
+
+func (g *Game) unmute(p *Player) {
+	p.mutedUntil = time.Time{}
+	g.saveMute(p)
+}
+
+// saveMute keeps the mute of a player with its account, so that logging in again doesn't lift it.
+func (g *Game) saveMute(p *Player) {
+	if g.store == nil {
+		return
+	}
+	a, err := g.store.LoadAccount(p.Name())
+	if err != nil {
+		g.storeFailed("account", p.Name(), err)
+		return
+	}
+	a.MutedUntil = nil
+	if !p.mutedUntil.IsZero() {
+		until := p.mutedUntil
+		a.MutedUntil = &until
+	}
+	if err := g.store.SaveAccount(a); err != nil {
+		g.storeFailed("account", p.Name(), err)
+	}
+}
+
+// duration reads a duration in minutes and seconds, like "1분 30초".
+func duration(d time.Duration) string {
+	d = d.Round(time.Second)
+	minutes, seconds := int(d/time.Minute), int(d%time.Minute/time.Second)
+	switch {
+	case minutes == 0:
+		return strconv.Itoa(seconds) + "초"
+	case seconds == 0:
+		return strconv.Itoa(minutes) + "분"
+	}
+	return strconv.Itoa(minutes) + "분 " + strconv.Itoa(seconds) + "초"
+}
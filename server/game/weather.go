@@ -0,0 +1,40 @@
+package game
+
+import "time"
+
+const weatherInterval = 5 * time.Minute
+
+type weather struct {
+	name   string
+	change string
+}
+
+var weathers = []weather{
+	{"맑음", "구름이 걷히고 해가 비칩니다."},
+	{"흐림", "하늘에 구름이 몰려듭니다."},
+	{"비", "빗방울이 떨어지기 시작합니다."},
+	{"눈", "하얀 눈송이가 흩날립니다."},
+}
+
+// changeWeather moves the weather to a neighbouring state now and then and tells everyone outdoors.
+func (g *Game) changeWeather() {
+	next := g.weather
+	switch g.rng.Intn(4) {
+	case 0:
+		next--
+	case 1:
+		next++
+	default:
+		return
+	}
+	if next < 0 || next >= len(weathers) {
+		return
+	}
+	g.weather = next
+
+	for _, p := range g.sortedPlayers() {
+		if r, ok := g.world.Room(p.Room()); ok && r.Outdoor {
+			p.Send("{c}" + weathers[next].change + "{x}")
+		}
+	}
+}
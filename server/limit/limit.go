@@ -0,0 +1,188 @@
+package limit
+
+import (
+	"errors"
+	"expvar"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSession = errors.New("too many requests from the session")
+	ErrAddress = errors.New("too many requests from the address")
+)
+
+// Metrics count what the limits did since the server started. They are published with expvar
+// under "limits".
+var Metrics = expvar.NewMap("limits")
+
+const (
+	MetricSession = "rejected_session"
+	MetricAddress = "rejected_address"
+	MetricRepeat  = "repeated_messages"
+	MetricMute    = "mutes"
+)
+
+// Config are the limits of the server. Rates are requests per second, bursts how many requests
+// can come at once after a quiet while. A zero or negative rate turns that limit off, a burst
+// below 1 counts as 1 so that a limit which is on still lets requests through at its rate.
+type Config struct {
+	SessionRate  float64
+	SessionBurst int
+	AddressRate  float64
+	AddressBurst int
+	// Repeats is how many times in a row a player may say the same thing within RepeatWindow
+	// before they are muted for MuteFor.
+	Repeats      int
+	RepeatWindow time.Duration
+	MuteFor      time.Duration
+}
+
+var DefaultConfig = Config{
+	SessionRate:  10,
+	SessionBurst: 20,
+	AddressRate:  30,
+	AddressBurst: 60,
+	Repeats:      3,
+	RepeatWindow: 30 * time.Second,
+	MuteFor:      time.Minute,
+}
+
+// Bucket is a token bucket: it holds up to burst tokens, refills at rate tokens per second and
+// every request takes one. A burst below 1 is taken as 1, less would refuse every request.
+type Bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewBucket(rate float64, burst int, now time.Time) *Bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+func (b *Bucket) Allow(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
+
+// full tells whether the bucket was left alone long enough to forget about it.
+func (b *Bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+// maxBuckets bounds the buckets of a Limiter, idle ones are dropped beyond it.
+const maxBuckets = 10000
+
+// Limiter keeps a bucket per session and per address. It is safe for concurrent use.
+type Limiter struct {
+	mutex    sync.Mutex
+	cfg      Config
+	now      func() time.Time
+	sessions map[string]*Bucket
+	address  map[string]*Bucket
+}
+
+// New creates a limiter. A zero or negative rate turns that limit off and a burst below 1
+// counts as 1.
+func New(cfg Config, now func() time.Time) *Limiter {
+	if now == nil {
+		now = time.Now
+	}
+	return &Limiter{
+		cfg:      cfg,
+		now:      now,
+		sessions: make(map[string]*Bucket),
+		address:  make(map[string]*Bucket),
+	}
+}
+
+// Allow takes a token for a request of a session coming from an address. Either may be empty
+// when it isn't known.
+func (l *Limiter) Allow(session, address string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if address != "" && l.cfg.AddressRate > 0 &&
+		!l.bucket(l.address, address, l.cfg.AddressRate, l.cfg.AddressBurst, now).Allow(now) {
+		Metrics.Add(MetricAddress, 1)
+		return ErrAddress
+	}
+	if session != "" && l.cfg.SessionRate > 0 &&
+		!l.bucket(l.sessions, session, l.cfg.SessionRate, l.cfg.SessionBurst, now).Allow(now) {
+		Metrics.Add(MetricSession, 1)
+		return ErrSession
+	}
+	return nil
+}
+
+// Forget drops the bucket of a session that ended.
+func (l *Limiter) Forget(session string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.sessions, session)
+}
+
+func (l *Limiter) bucket(buckets map[string]*Bucket, key string, rate float64, burst int, now time.Time) *Bucket {
+	if b, ok := buckets[key]; ok {
+		return b
+	}
+	if len(buckets) >= maxBuckets {
+		for k, b := range buckets {
+			if b.full(now) {
+				delete(buckets, k)
+			}
+		}
+	}
+	b := NewBucket(rate, burst, now)
+	buckets[key] = b
+	return b
+}
+
+// Repeats notices a player saying the same thing over and over.
+type Repeats struct {
+	text  string
+	count int
+	last  time.Time
+}
+
+// Add records a message and returns how many times in a row it was said, counting only
+// messages less than window apart. Case and spacing don't make a message different.
+func (r *Repeats) Add(text string, now time.Time, window time.Duration) int {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if text == r.text && now.Sub(r.last) < window {
+		r.count++
+	} else {
+		r.text, r.count = text, 1
+	}
+	r.last = now
+	if r.count > 1 {
+		Metrics.Add(MetricRepeat, 1)
+	}
+	return r.count
+}
+
+func (r *Repeats) Reset() {
+	*r = Repeats{}
+}
@@ -0,0 +1,187 @@
+package npc
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zrma/mud/world"
+)
+
+// World is what a behavior sees of the game around one mob. The game implements it for every
+// mob it runs. Every method is called with the game lock held.
+type World interface {
+	// Room is the room the mob is in, Home the room it spawned in.
+	Room() string
+	Home() string
+	// Exits maps the directions out of the room to the rooms they lead to, limited to the area
+	// of the mob.
+	Exits() map[string]string
+	// Players are the names of the players in the room.
+	Players() []string
+	// Attackers are the names of the mobs in the room fighting a player.
+	Attackers() []string
+	Fighting() bool
+	// Execute runs a command line as the mob, through the same commands players use.
+	Execute(line string)
+	Intn(n int) int
+}
+
+// Behavior drives a mob. Tick runs on every AI tick, Arrive when a player comes into the room
+// and Hear when a player says something in it.
+type Behavior interface {
+	Tick(w World)
+	Arrive(w World, player string)
+	Hear(w World, speaker, msg string)
+}
+
+// New builds the behaviors of a mob template. Greeting and dialog come last, so a mob that
+// attacks on sight doesn't say hello first.
+func New(t *world.MobTemplate) []Behavior {
+	var behaviors []Behavior
+	for _, b := range t.Behaviors {
+		switch b {
+		case world.BehaviorWander:
+			behaviors = append(behaviors, wander{})
+		case world.BehaviorAggressive:
+			behaviors = append(behaviors, aggressive{})
+		case world.BehaviorGuard:
+			behaviors = append(behaviors, guard{})
+		case world.BehaviorShopkeeper:
+			behaviors = append(behaviors, shopkeeper{})
+		}
+	}
+	if t.Greeting != "" || len(t.Dialog) > 0 {
+		behaviors = append(behaviors, dialog{greeting: t.Greeting, lines: t.Dialog})
+	}
+	return behaviors
+}
+
+// defaultRespawn is used for mob templates without a respawn time of their own.
+const defaultRespawn = 30 * time.Second
+
+// RespawnDelay is how long a killed mob of template t stays away.
+func RespawnDelay(t *world.MobTemplate) time.Duration {
+	if t.Respawn > 0 {
+		return time.Duration(t.Respawn) * time.Second
+	}
+	return defaultRespawn
+}
+
+// idle does nothing, behaviors embed it for the hooks they don't need.
+type idle struct{}
+
+func (idle) Tick(w World)                      {}
+func (idle) Arrive(w World, player string)     {}
+func (idle) Hear(w World, speaker, msg string) {}
+
+const wanderChance = 4 // one in
+
+// wander walks through a random exit now and then.
+type wander struct {
+	idle
+}
+
+func (wander) Tick(w World) {
+	if w.Fighting() || w.Intn(wanderChance) != 0 {
+		return
+	}
+	exits := sortedExits(w.Exits())
+	if len(exits) == 0 {
+		return
+	}
+	w.Execute(exits[w.Intn(len(exits))] + " 가")
+}
+
+// aggressive attacks a player who comes in, or one that is already there.
+type aggressive struct {
+	idle
+}
+
+func (aggressive) Tick(w World) {
+	if w.Fighting() {
+		return
+	}
+	if players := w.Players(); len(players) > 0 {
+		w.Execute(players[w.Intn(len(players))] + " 공격")
+	}
+}
+
+func (aggressive) Arrive(w World, player string) {
+	if !w.Fighting() {
+		w.Execute(player + " 공격")
+	}
+}
+
+// guard steps in when a mob attacks a player in its room and walks back home when it was
+// lured away.
+type guard struct {
+	idle
+}
+
+func (guard) Tick(w World) {
+	if w.Fighting() {
+		return
+	}
+	if attackers := w.Attackers(); len(attackers) > 0 {
+		w.Execute(attackers[0] + " 공격")
+		return
+	}
+	if w.Room() == w.Home() {
+		return
+	}
+	for _, dir := range sortedExits(w.Exits()) {
+		if w.Exits()[dir] == w.Home() {
+			w.Execute(dir + " 가")
+			return
+		}
+	}
+}
+
+// shopkeeper welcomes customers. Buying and selling are player commands that look for a
+// shopkeeper in the room.
+type shopkeeper struct {
+	idle
+}
+
+func (shopkeeper) Arrive(w World, player string) {
+	if !w.Fighting() {
+		w.Execute("어서 오세요. 물건을 보시려면 목록이라고 하세요. 말")
+	}
+}
+
+// dialog greets players and answers when a player says one of its words.
+type dialog struct {
+	idle
+	greeting string
+	lines    map[string]string
+}
+
+func (d dialog) Arrive(w World, player string) {
+	if d.greeting != "" && !w.Fighting() {
+		w.Execute(d.greeting + " 말")
+	}
+}
+
+func (d dialog) Hear(w World, speaker, msg string) {
+	words := make([]string, 0, len(d.lines))
+	for word := range d.lines {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	for _, word := range words {
+		if strings.Contains(msg, word) {
+			w.Execute(d.lines[word] + " 말")
+			return
+		}
+	}
+}
+
+func sortedExits(exits map[string]string) []string {
+	dirs := make([]string, 0, len(exits))
+	for dir := range exits {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
@@ -0,0 +1,155 @@
+package npc_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zrma/mud/server/npc"
+	"github.com/zrma/mud/world"
+)
+
+// fakeWorld is the room of one mob. Intn answers with rolls in order, 0 once they run out.
+type fakeWorld struct {
+	room      string
+	home      string
+	exits     map[string]string
+	players   []string
+	attackers []string
+	fighting  bool
+	rolls     []int
+
+	executed []string
+}
+
+func (w *fakeWorld) Room() string             { return w.room }
+func (w *fakeWorld) Home() string             { return w.home }
+func (w *fakeWorld) Exits() map[string]string { return w.exits }
+func (w *fakeWorld) Players() []string        { return w.players }
+func (w *fakeWorld) Attackers() []string      { return w.attackers }
+func (w *fakeWorld) Fighting() bool           { return w.fighting }
+
+// Execute records the line. An attack starts a fight, like it does in the game.
+func (w *fakeWorld) Execute(line string) {
+	w.executed = append(w.executed, line)
+	if strings.HasSuffix(line, " 공격") {
+		w.fighting = true
+	}
+}
+
+func (w *fakeWorld) Intn(n int) int {
+	if len(w.rolls) == 0 {
+		return 0
+	}
+	r := w.rolls[0] % n
+	w.rolls = w.rolls[1:]
+	return r
+}
+
+func behaviors(behaviors ...string) []npc.Behavior {
+	return npc.New(&world.MobTemplate{ID: "mob", Behaviors: behaviors})
+}
+
+func tick(w *fakeWorld, bs []npc.Behavior) []string {
+	for _, b := range bs {
+		b.Tick(w)
+	}
+	return w.executed
+}
+
+func arrive(w *fakeWorld, bs []npc.Behavior, player string) []string {
+	for _, b := range bs {
+		b.Arrive(w, player)
+	}
+	return w.executed
+}
+
+func check(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: executed %q, want %q", what, got, want)
+	}
+}
+
+func TestWander(t *testing.T) {
+	wander := behaviors(world.BehaviorWander)
+	exits := map[string]string{"북": "north", "남": "south"}
+
+	w := &fakeWorld{exits: exits, rolls: []int{0, 1}}
+	check(t, "wandering", tick(w, wander), []string{"북 가"})
+
+	w = &fakeWorld{exits: exits, rolls: []int{1}}
+	check(t, "staying", tick(w, wander), nil)
+
+	w = &fakeWorld{exits: exits, fighting: true}
+	check(t, "fighting", tick(w, wander), nil)
+
+	w = &fakeWorld{}
+	check(t, "no exits", tick(w, wander), nil)
+}
+
+func TestAggressive(t *testing.T) {
+	aggressive := behaviors(world.BehaviorAggressive)
+
+	w := &fakeWorld{players: []string{"철수", "영희"}, rolls: []int{1}}
+	check(t, "tick", tick(w, aggressive), []string{"영희 공격"})
+
+	w = &fakeWorld{}
+	check(t, "nobody there", tick(w, aggressive), nil)
+
+	w = &fakeWorld{}
+	check(t, "arrival", arrive(w, aggressive, "철수"), []string{"철수 공격"})
+
+	w = &fakeWorld{players: []string{"철수"}, fighting: true}
+	check(t, "fighting", append(tick(w, aggressive), arrive(w, aggressive, "영희")...), nil)
+}
+
+func TestGuard(t *testing.T) {
+	guard := behaviors(world.BehaviorGuard)
+
+	w := &fakeWorld{room: "gate", home: "gate", attackers: []string{"늑대", "곰"}}
+	check(t, "attacker", tick(w, guard), []string{"늑대 공격"})
+
+	w = &fakeWorld{room: "road", home: "gate", exits: map[string]string{"동": "field", "서": "gate"}}
+	check(t, "lured away", tick(w, guard), []string{"서 가"})
+
+	w = &fakeWorld{room: "gate", home: "gate", exits: map[string]string{"동": "road"}}
+	check(t, "home", tick(w, guard), nil)
+
+	w = &fakeWorld{room: "road", home: "gate", attackers: []string{"늑대"}, fighting: true}
+	check(t, "fighting", tick(w, guard), nil)
+}
+
+func TestShopkeeper(t *testing.T) {
+	shopkeeper := behaviors(world.BehaviorShopkeeper)
+
+	w := &fakeWorld{}
+	check(t, "customer", arrive(w, shopkeeper, "철수"), []string{"어서 오세요. 물건을 보시려면 목록이라고 하세요. 말"})
+
+	w = &fakeWorld{fighting: true}
+	check(t, "fighting", arrive(w, shopkeeper, "철수"), nil)
+
+	w = &fakeWorld{players: []string{"철수"}}
+	check(t, "tick", tick(w, shopkeeper), nil)
+}
+
+// TestGreetingLast checks that a mob attacking on sight doesn't greet the player it attacks.
+func TestGreetingLast(t *testing.T) {
+	bs := npc.New(&world.MobTemplate{
+		ID:        "mob",
+		Behaviors: []string{world.BehaviorAggressive},
+		Greeting:  "안녕",
+	})
+	w := &fakeWorld{}
+	check(t, "arrival", arrive(w, bs, "철수"), []string{"철수 공격"})
+}
+
+func TestRespawnDelay(t *testing.T) {
+	if d := npc.RespawnDelay(&world.MobTemplate{Respawn: 90}); d != 90*time.Second {
+		t.Errorf("respawn after %v, want 90s", d)
+	}
+	if d := npc.RespawnDelay(&world.MobTemplate{}); d <= 0 {
+		t.Errorf("respawn after %v without a time of its own, want the default", d)
+	}
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zrma/mud/event"
+	"github.com/zrma/mud/markup"
+)
+
+// The renderer turns the structured views sent to rich clients into the color markup sent as
+// ReceiveReply.msg, so both always describe the same thing.
+
+const separator = ", "
+
+func Room(r event.Room) string {
+	lines := []string{
+		"{W}" + markup.Escape(r.Name) + "{x}",
+		markup.Escape(r.Description),
+	}
+	if len(r.Exits) > 0 {
+		lines = append(lines, "{c}출구:{x} "+list(r.Exits))
+	} else {
+		lines = append(lines, "{c}출구:{x} 없음")
+	}
+	if len(r.Items) > 0 {
+		lines = append(lines, "{y}바닥:{x} "+list(r.Items))
+	}
+	if len(r.Mobs) > 0 || len(r.Players) > 0 {
+		lines = append(lines, "{g}여기 있는 이:{x} "+list(append(append([]string(nil), r.Mobs...), r.Players...)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func Target(t event.Target) string {
+	lines := []string{"{W}" + markup.Escape(t.Name) + "{x}"}
+	if t.Description != "" {
+		lines = append(lines, markup.Escape(t.Description))
+	} else {
+		lines = append(lines, "특별한 점은 보이지 않습니다.")
+	}
+	if t.Kind == "container" {
+		if len(t.Contents) > 0 {
+			lines = append(lines, "{y}안에 든 것:{x} "+list(t.Contents))
+		} else {
+			lines = append(lines, "{y}안에 든 것:{x} 없음")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func list(names []string) string {
+	escaped := make([]string, 0, len(names))
+	for _, n := range names {
+		escaped = append(escaped, markup.Escape(n))
+	}
+	return strings.Join(escaped, separator)
+}
+
+func Inventory(items []string, equipment map[string]string, slots []string) string {
+	lines := []string{"{W}소지품{x}"}
+	if len(items) > 0 {
+		lines = append(lines, list(items))
+	} else {
+		lines = append(lines, "가진 것이 없습니다.")
+	}
+	lines = append(lines, "{W}장비{x}")
+	for _, slot := range slots {
+		name, ok := equipment[slot]
+		if !ok {
+			name = "-"
+		}
+		lines = append(lines, "{c}"+markup.Escape(slot)+":{x} "+markup.Escape(name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func Score(s event.Stats) string {
+	lines := []string{
+		"{W}" + markup.Escape(s.Name) + "{x}  레벨 " + strconv.Itoa(s.Level),
+		fmt.Sprintf("{c}경험치:{x} %d / %d", s.Exp, s.ExpNext),
+		fmt.Sprintf("{R}체력:{x} %d / %d  {B}마력:{x} %d / %d  {G}기력:{x} %d / %d",
+			s.HP, s.MaxHP, s.MP, s.MaxMP, s.Stamina, s.MaxStamina),
+		fmt.Sprintf("{Y}골드:{x} %d", s.Gold),
+		fmt.Sprintf("힘 %d  민첩 %d  체질 %d  지능 %d  지혜 %d",
+			s.Strength, s.Dexterity, s.Constitution, s.Intelligence, s.Wisdom),
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+)
+
+var ErrInterval = errors.New("scheduler: non-positive interval")
+
+// Scheduler runs functions at points of game time. Time only moves when Advance is called, so
+// the owner decides when tasks run and which lock they run under. It isn't safe for concurrent
+// use on its own.
+type Scheduler struct {
+	now    time.Time
+	nextID uint64
+	tasks  taskHeap
+	byID   map[uint64]*task
+}
+
+type Timer uint64
+
+type task struct {
+	id       uint64
+	at       time.Time
+	interval time.Duration
+	fn       func()
+	index    int
+}
+
+func New(now time.Time) *Scheduler {
+	return &Scheduler{
+		now:  now,
+		byID: make(map[uint64]*task),
+	}
+}
+
+func (s *Scheduler) Now() time.Time {
+	return s.now
+}
+
+// After runs fn once, d after the current game time.
+func (s *Scheduler) After(d time.Duration, fn func()) Timer {
+	return s.add(d, 0, fn)
+}
+
+// Every runs fn every d, starting d after the current game time. A non-positive d would run fn
+// forever without moving time, so it is refused with ErrInterval.
+func (s *Scheduler) Every(d time.Duration, fn func()) (Timer, error) {
+	if d <= 0 {
+		return 0, ErrInterval
+	}
+	return s.add(d, d, fn), nil
+}
+
+func (s *Scheduler) Cancel(t Timer) bool {
+	task, ok := s.byID[uint64(t)]
+	if !ok {
+		return false
+	}
+	// tasks set aside by Advance are out of the heap, they are left out when put back
+	if task.index >= 0 {
+		heap.Remove(&s.tasks, task.index)
+	}
+	delete(s.byID, task.id)
+	return true
+}
+
+// Advance moves game time to now and runs every task that came due, in order of their due time
+// and then of scheduling. A repeating task that fell behind runs once per missed interval. Tasks
+// scheduled while Advance runs wait for the next call, even when they are due already, so that a
+// task scheduling itself again can't keep Advance from returning.
+func (s *Scheduler) Advance(now time.Time) {
+	last := s.nextID
+	var added []*task
+	for len(s.tasks) > 0 {
+		next := s.tasks[0]
+		if next.at.After(now) {
+			break
+		}
+		if next.id > last {
+			heap.Pop(&s.tasks)
+			added = append(added, next)
+			continue
+		}
+		s.now = next.at
+
+		if next.interval > 0 {
+			next.at = next.at.Add(next.interval)
+			heap.Fix(&s.tasks, next.index)
+		} else {
+			heap.Pop(&s.tasks)
+			delete(s.byID, next.id)
+		}
+		next.fn()
+	}
+	for _, t := range added {
+		if _, ok := s.byID[t.id]; ok {
+			heap.Push(&s.tasks, t)
+		}
+	}
+	if now.After(s.now) {
+		s.now = now
+	}
+}
+
+func (s *Scheduler) add(d, interval time.Duration, fn func()) Timer {
+	s.nextID++
+	t := &task{
+		id:       s.nextID,
+		at:       s.now.Add(d),
+		interval: interval,
+		fn:       fn,
+	}
+	heap.Push(&s.tasks, t)
+	s.byID[t.id] = t
+	return Timer(t.id)
+}
+
+type taskHeap []*task
+
+func (h taskHeap) Len() int {
+	return len(h)
+}
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].id < h[j].id
+	}
+	return h[i].at.Before(h[j].at)
+}
+
+func (h taskHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *taskHeap) Push(x interface{}) {
+	t := x.(*task)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	t.index = -1
+	*h = old[:len(old)-1]
+	return t
+}
@@ -0,0 +1,159 @@
+package scheduler_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zrma/mud/server/clock"
+	"github.com/zrma/mud/server/scheduler"
+)
+
+var start = time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// step advances the clock by d and the scheduler along with it, the way the game loop does.
+func step(c *clock.Fake, s *scheduler.Scheduler, d time.Duration) {
+	c.Advance(d)
+	s.Advance(c.Now())
+}
+
+func TestEveryAndAfter(t *testing.T) {
+	c := clock.NewFake(start)
+	s := scheduler.New(c.Now())
+
+	var runs []string
+	if _, err := s.Every(2*time.Second, func() {
+		runs = append(runs, "every "+s.Now().Sub(start).String())
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.After(3*time.Second, func() {
+		runs = append(runs, "after "+s.Now().Sub(start).String())
+	})
+
+	for i := 0; i < 5; i++ {
+		step(c, s, time.Second)
+	}
+	want := []string{"every 2s", "after 3s", "every 4s"}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs = %v, want %v", runs, want)
+	}
+	if !s.Now().Equal(c.Now()) {
+		t.Errorf("scheduler at %v, clock at %v", s.Now(), c.Now())
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	c := clock.NewFake(start)
+	s := scheduler.New(c.Now())
+
+	n := 0
+	if _, err := s.Every(time.Second, func() { n++ }); err != nil {
+		t.Fatal(err)
+	}
+	step(c, s, 5*time.Second)
+	if n != 5 {
+		t.Errorf("ran %d times after falling 5 intervals behind, want 5", n)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	s := scheduler.New(start)
+
+	var runs []int
+	for i := 0; i < 3; i++ {
+		i := i
+		s.After(time.Second, func() { runs = append(runs, i) })
+	}
+	s.After(time.Millisecond, func() { runs = append(runs, -1) })
+	s.Advance(start.Add(time.Second))
+
+	want := []int{-1, 0, 1, 2}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("runs = %v, want %v", runs, want)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	s := scheduler.New(start)
+
+	ran := false
+	timer := s.After(time.Second, func() { ran = true })
+	if !s.Cancel(timer) {
+		t.Fatal("cancelling a pending timer failed")
+	}
+	if s.Cancel(timer) {
+		t.Error("cancelling a timer twice succeeded")
+	}
+	s.Advance(start.Add(time.Minute))
+	if ran {
+		t.Error("a cancelled timer ran")
+	}
+}
+
+func TestEveryNonPositive(t *testing.T) {
+	s := scheduler.New(start)
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, err := s.Every(d, func() {}); err != scheduler.ErrInterval {
+			t.Errorf("Every(%v): err = %v, want ErrInterval", d, err)
+		}
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	c := clock.NewFake(start)
+	ticker := c.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	select {
+	case <-ticker.C():
+		t.Fatal("ticked before the clock moved")
+	default:
+	}
+
+	// ticks nobody received are dropped, like those of time.Ticker
+	c.Advance(time.Second)
+	select {
+	case now := <-ticker.C():
+		if want := start.Add(100 * time.Millisecond); !now.Equal(want) {
+			t.Errorf("tick at %v, want %v", now, want)
+		}
+	default:
+		t.Fatal("no tick after the clock moved")
+	}
+	select {
+	case <-ticker.C():
+		t.Error("more than one tick buffered")
+	default:
+	}
+}
+
+func TestScheduledWhileAdvancing(t *testing.T) {
+	s := scheduler.New(start)
+
+	n := 0
+	var again func()
+	again = func() {
+		n++
+		s.After(0, again)
+	}
+	s.After(0, again)
+	cancelled := false
+	s.After(time.Second, func() {
+		timer := s.After(0, func() { cancelled = true })
+		s.Cancel(timer)
+	})
+
+	s.Advance(start.Add(time.Second))
+	if n != 1 {
+		t.Errorf("a task scheduling itself ran %d times in one Advance, want 1", n)
+	}
+	s.Advance(start.Add(time.Second))
+	if n != 2 {
+		t.Errorf("the task scheduled during the last Advance ran %d times in total, want 2", n)
+	}
+	s.Advance(start.Add(time.Minute))
+	if cancelled {
+		t.Error("a task cancelled while Advance set it aside ran")
+	}
+}
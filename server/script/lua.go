@@ -0,0 +1,194 @@
+package script
+
+import (
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/zrma/mud/josa"
+)
+
+// newState opens only the libraries that can't reach outside of the game, like the client does
+// for its scripts, and none of the functions that reach the globals every script shares. The
+// stacks are fixed in size so that deep recursion fails instead of growing.
+func newState(e *Engine) *lua.LState {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:  true,
+		CallStackSize: callStackSize,
+		RegistrySize:  registrySize,
+	})
+
+	for _, lib := range []struct {
+		name string
+		f    lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.f))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{
+		"dofile", "loadfile", "require", "module", "print", "collectgarbage",
+		"load", "loadstring", "getfenv", "setfenv", "_G", "_printregs",
+	} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	// The metatable of strings is shared by every script, and its __index is the string library.
+	// getmetatable only gives out those of tables, and setmetatable refuses to replace it.
+	if mt, ok := L.GetMetatable(lua.LString("")).(*lua.LTable); ok {
+		mt.RawSetString("__metatable", lua.LFalse)
+	}
+	L.SetGlobal("getmetatable", L.NewFunction(func(L *lua.LState) int {
+		if t, ok := L.CheckAny(1).(*lua.LTable); ok {
+			L.Push(L.GetMetatable(t))
+			return 1
+		}
+		L.Push(lua.LNil)
+		return 1
+	}))
+
+	for _, name := range []string{lua.StringLibName, lua.TabLibName} {
+		if lib, ok := L.GetGlobal(name).(*lua.LTable); ok {
+			countLib(e, L, lib)
+		}
+	}
+
+	// string.rep can allocate a lot in a single call, so it is counted before it allocates.
+	if str, ok := L.GetGlobal("string").(*lua.LTable); ok {
+		str.RawSetString("rep", L.NewFunction(func(L *lua.LState) int {
+			s, n := L.CheckString(1), L.CheckInt(2)
+			if n < 0 {
+				n = 0
+			}
+			e.allocate(L, uint64(len(s))*uint64(n))
+			L.Push(lua.LString(strings.Repeat(s, n)))
+			return 1
+		}))
+	}
+
+	api := map[string]lua.LGFunction{
+		// send(actor, "text") shows a line to a player.
+		"send": func(L *lua.LState) int {
+			check(L, e.api.Send(checkRef(L, 1), L.CheckString(2)))
+			return 0
+		},
+		// send_room(room, "text") shows a line to everyone in a room, given as ref or id.
+		"send_room": func(L *lua.LState) int {
+			check(L, e.api.SendRoom(checkRoom(L, 1), L.CheckString(2)))
+			return 0
+		},
+		// move(actor, room) takes a player or mob to another room.
+		"move": func(L *lua.LState) int {
+			check(L, e.api.Move(checkRef(L, 1), checkRoom(L, 2)))
+			return 0
+		},
+		// stat(actor, "hp") and set_stat(actor, "hp", 10). The stats are hp, mp, stamina, exp and
+		// gold.
+		"stat": func(L *lua.LState) int {
+			v, err := e.api.Stat(checkRef(L, 1), L.CheckString(2))
+			check(L, err)
+			L.Push(lua.LNumber(v))
+			return 1
+		},
+		"set_stat": func(L *lua.LState) int {
+			check(L, e.api.SetStat(checkRef(L, 1), L.CheckString(2), L.CheckInt(3)))
+			return 0
+		},
+		// execute(self, "안녕하세요 말") makes a mob run a command.
+		"execute": func(L *lua.LState) int {
+			check(L, e.api.Execute(checkRef(L, 1), L.CheckString(2)))
+			return 0
+		},
+		// players(room) lists the players in a room.
+		"players": func(L *lua.LState) int {
+			tbl := L.NewTable()
+			for _, r := range e.api.Players(checkRoom(L, 1)) {
+				tbl.Append(e.ref(r))
+			}
+			e.allocate(L, uint64(tbl.Len())*refSize)
+			L.Push(tbl)
+			return 1
+		},
+		// format("{actor:이/가} 웃습니다.", {actor = actor}) fills in names with the particles
+		// that fit them. Values are strings or refs, which stand for their name.
+		"format": func(L *lua.LState) int {
+			template := L.CheckString(1)
+			var kv []string
+			L.OptTable(2, L.NewTable()).ForEach(func(k, v lua.LValue) {
+				if t, ok := v.(*lua.LTable); ok {
+					v = t.RawGetString("name")
+				}
+				kv = append(kv, lua.LVAsString(k), lua.LVAsString(v))
+			})
+			text := josa.Format(template, kv...)
+			e.allocate(L, uint64(len(text)))
+			L.Push(lua.LString(text))
+			return 1
+		},
+		// after(seconds, function() end) runs later in game time, at most maxAfter later. Every
+		// pending call counts against the memory limit and there are at most maxPending of them.
+		"after": func(L *lua.LState) int {
+			seconds := float64(L.CheckNumber(1))
+			if !(seconds > 0) || seconds > maxAfter.Seconds() {
+				L.ArgError(1, "delay must be a positive number of seconds up to "+maxAfter.String())
+			}
+			fn := L.CheckFunction(2)
+			if e.pending >= maxPending {
+				L.RaiseError("too many pending after calls")
+			}
+			e.allocate(L, taskSize)
+			e.pending++
+			e.api.After(time.Duration(seconds*float64(time.Second)), func() {
+				e.pending--
+				if e.closed {
+					return
+				}
+				if err := e.run(fn); err != nil && e.report != nil {
+					e.report("after", err)
+				}
+			})
+			return 0
+		},
+	}
+	for name, f := range api {
+		L.SetGlobal(name, L.NewFunction(f))
+	}
+	return L
+}
+
+// countLib makes the functions of a library count what they allocate: a slot for every call, like
+// the one table.insert takes, and the bytes of the strings they return.
+func countLib(e *Engine, L *lua.LState, lib *lua.LTable) {
+	funcs := make(map[string]lua.LGFunction)
+	lib.ForEach(func(k, v lua.LValue) {
+		if fn, ok := v.(*lua.LFunction); ok && fn.IsG {
+			funcs[lua.LVAsString(k)] = fn.GFunction
+		}
+	})
+	for name, f := range funcs {
+		f := f
+		lib.RawSetString(name, L.NewFunction(func(L *lua.LState) int {
+			n := f(L)
+			size := uint64(slotSize)
+			for i := 1; i <= n; i++ {
+				if s, ok := L.Get(-i).(lua.LString); ok {
+					size += uint64(len(s))
+				}
+			}
+			e.allocate(L, size)
+			return n
+		}))
+	}
+}
+
+func check(L *lua.LState, err error) {
+	if err != nil {
+		L.RaiseError(err.Error())
+	}
+}
@@ -0,0 +1,382 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/ast"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/zrma/mud/world"
+)
+
+// Hooks the game calls. A script defines the ones it needs as global functions, which get the
+// scripted room, item or mob as their first argument.
+const (
+	OnEnter = "on_enter"
+	OnSay   = "on_say"
+	OnGive  = "on_give"
+	OnDeath = "on_death"
+	OnTick  = "on_tick"
+)
+
+// Kinds of Ref.
+const (
+	KindPlayer = "player"
+	KindMob    = "mob"
+	KindItem   = "item"
+	KindRoom   = "room"
+)
+
+var (
+	ErrTimeout = errors.New("script ran out of time")
+	ErrMemory  = errors.New("script allocated too much memory")
+	ErrKept    = errors.New("script kept too much memory and was unloaded")
+	ErrDepth   = errors.New("scripts call each other too deep")
+)
+
+// Ref is how scripts see a player, mob, item or room: a table with kind, id and name.
+type Ref struct {
+	Kind string
+	ID   string
+	Name string
+}
+
+// API is what scripts can do to the game. Errors are raised in the script as Lua errors.
+type API interface {
+	Send(to Ref, msg string) error
+	SendRoom(room, msg string) error
+	Move(who Ref, room string) error
+	Stat(who Ref, name string) (int, error)
+	SetStat(who Ref, name string, value int) error
+	// Execute runs a command line as a mob, through the commands players use.
+	Execute(who Ref, line string) error
+	Players(room string) []Ref
+	After(d time.Duration, f func())
+}
+
+// Limits bound a single hook call, including everything it triggers. Memory bounds two things:
+// what the call allocates through the library and game functions, like the strings they return,
+// even when the script drops it again, and what each script keeps in its globals and the
+// upvalues of its functions once the call is done. A script over the second bound is unloaded.
+// What a call builds in between with plain Lua, like by concatenating strings in a loop, isn't
+// counted until it is kept; while the call runs only the timeout bounds it. The Lua stacks and
+// registry have fixed sizes of their own.
+type Limits struct {
+	Timeout time.Duration
+	Memory  uint64
+}
+
+var DefaultLimits = Limits{
+	Timeout: 50 * time.Millisecond,
+	Memory:  16 << 20,
+}
+
+const (
+	maxDepth      = 8
+	callStackSize = 200
+	registrySize  = 1024 * 16
+	// slotSize, refSize and taskSize are what a table slot, a ref and a pending after call count
+	// against the memory limit.
+	slotSize = 16
+	refSize  = 256
+	taskSize = 256
+	// maxPending is how many after calls an engine keeps waiting at once, maxAfter how far ahead
+	// they can be.
+	maxPending = 1024
+	maxAfter   = 24 * time.Hour
+)
+
+// Engine runs the scripts of a world in one sandboxed Lua state. Each script gets its own copy of
+// the globals and the libraries so that scripts don't see or change each other. It is not safe
+// for concurrent use, the game calls it with its lock held.
+type Engine struct {
+	state   *lua.LState
+	api     API
+	limits  Limits
+	report  func(name string, err error)
+	scripts map[string]*lua.LTable
+	depth   int
+	closed  bool
+	// pending counts the after calls that haven't run yet.
+	pending int
+	// touched are the environments of the scripts the running call ran, to measure what they
+	// keep once it is done.
+	touched map[*lua.LTable]bool
+
+	// allocated is what the running call allocated so far, and exceeded tells whether that was
+	// more than the limit.
+	allocated uint64
+	exceeded  bool
+}
+
+// New creates an engine. report receives the errors of calls the game doesn't wait for, like
+// those of after.
+func New(api API, limits Limits, report func(name string, err error)) *Engine {
+	e := &Engine{
+		api:     api,
+		limits:  limits,
+		report:  report,
+		scripts: make(map[string]*lua.LTable),
+		touched: make(map[*lua.LTable]bool),
+	}
+	e.state = newState(e)
+	return e
+}
+
+// Close drops the Lua state. Pending after calls of a closed engine do nothing.
+func (e *Engine) Close() {
+	e.closed = true
+	e.state.Close()
+}
+
+// Check compiles source without running it.
+func Check(name, source string) error {
+	_, err := compile(name, source)
+	return err
+}
+
+func compile(name, source string) (*lua.FunctionProto, error) {
+	chunk, err := parseChunk(name, source)
+	if err != nil {
+		return nil, err
+	}
+	return lua.Compile(chunk, name)
+}
+
+// Load runs the top level of a script, which usually just defines its hooks.
+func (e *Engine) Load(name, source string) error {
+	proto, err := compile(name, source)
+	if err != nil {
+		return err
+	}
+
+	env := e.environment()
+	fn := e.state.NewFunctionFromProto(proto)
+	fn.Env = env
+	if err := e.run(fn); err != nil {
+		return err
+	}
+	e.scripts[name] = env
+	return nil
+}
+
+// environment copies the globals for a script, and the libraries among them, so that whatever the
+// script changes stays its own.
+func (e *Engine) environment() *lua.LTable {
+	env := e.state.NewTable()
+	e.state.Get(lua.GlobalsIndex).(*lua.LTable).ForEach(func(k, v lua.LValue) {
+		if lib, ok := v.(*lua.LTable); ok {
+			own := e.state.NewTable()
+			lib.ForEach(func(k, v lua.LValue) {
+				own.RawSet(k, v)
+			})
+			v = own
+		}
+		env.RawSet(k, v)
+	})
+	return env
+}
+
+func (e *Engine) Has(name, hook string) bool {
+	env, ok := e.scripts[name]
+	if !ok {
+		return false
+	}
+	_, ok = env.RawGetString(hook).(*lua.LFunction)
+	return ok
+}
+
+// Call runs a hook of a script if it defines it. Arguments can be Ref, string, int or nil.
+func (e *Engine) Call(name, hook string, args ...interface{}) error {
+	env, ok := e.scripts[name]
+	if !ok {
+		return nil
+	}
+	fn, ok := env.RawGetString(hook).(*lua.LFunction)
+	if !ok {
+		return nil
+	}
+
+	values := make([]lua.LValue, 0, len(args))
+	for _, arg := range args {
+		values = append(values, e.value(arg))
+	}
+	if err := e.run(fn, values...); err != nil {
+		return fmt.Errorf("%s: %s: %v", name, hook, err)
+	}
+	return nil
+}
+
+// run calls fn under the limits. Calls made while another one runs, like a hook fired by a
+// command a script executed, share the limits of the outermost call.
+func (e *Engine) run(fn *lua.LFunction, args ...lua.LValue) error {
+	if e.depth >= maxDepth {
+		return ErrDepth
+	}
+	e.depth++
+	defer func() { e.depth-- }()
+	if fn.Env != nil {
+		e.touched[fn.Env] = true
+	}
+
+	call := func() error {
+		return e.state.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, args...)
+	}
+	if e.depth > 1 {
+		return call()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.limits.Timeout)
+	defer cancel()
+
+	e.allocated, e.exceeded = 0, false
+	e.state.SetContext(ctx)
+	err := call()
+	e.state.RemoveContext()
+	if e.unloadOverLimit() {
+		return ErrMemory
+	}
+
+	switch {
+	case err == nil:
+		return nil
+	case e.exceeded:
+		return ErrMemory
+	case ctx.Err() == context.DeadlineExceeded:
+		return ErrTimeout
+	}
+	return err
+}
+
+// unloadOverLimit measures what the scripts the last call ran keep, and unloads those that keep
+// more than the memory limit. It tells whether there were any.
+func (e *Engine) unloadOverLimit() bool {
+	over := false
+	for env := range e.touched {
+		delete(e.touched, env)
+		if held(env, e.limits.Memory) <= e.limits.Memory {
+			continue
+		}
+		over = true
+		for name, other := range e.scripts {
+			if other == env {
+				delete(e.scripts, name)
+				if e.report != nil {
+					e.report(name, ErrKept)
+				}
+			}
+		}
+	}
+	return over
+}
+
+// held counts what a table holds: a slot for every entry and the bytes of its strings, through
+// nested tables, metatables and the upvalues of functions. It stops counting once it is over max.
+func held(t *lua.LTable, max uint64) uint64 {
+	var size uint64
+	seen := make(map[lua.LValue]bool)
+	var count func(v lua.LValue)
+	count = func(v lua.LValue) {
+		if size > max || seen[v] {
+			return
+		}
+		switch v := v.(type) {
+		case lua.LString:
+			size += uint64(len(v))
+		case *lua.LTable:
+			seen[v] = true
+			v.ForEach(func(k, v lua.LValue) {
+				size += slotSize
+				count(k)
+				count(v)
+			})
+			if v.Metatable != nil {
+				count(v.Metatable)
+			}
+		case *lua.LFunction:
+			seen[v] = true
+			for _, up := range v.Upvalues {
+				count(up.Value())
+			}
+		}
+	}
+	count(t)
+	return size
+}
+
+// allocate counts n bytes against the memory limit of the running call, and stops the script
+// once it is over the limit.
+func (e *Engine) allocate(L *lua.LState, n uint64) {
+	if e.exceeded || n > e.limits.Memory-e.allocated {
+		e.exceeded = true
+		L.RaiseError(ErrMemory.Error())
+	}
+	e.allocated += n
+}
+
+func (e *Engine) value(v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case Ref:
+		return e.ref(v)
+	case string:
+		return lua.LString(v)
+	case int:
+		return lua.LNumber(v)
+	case nil:
+		return lua.LNil
+	}
+	panic(fmt.Sprintf("script: unsupported argument %T", v))
+}
+
+func (e *Engine) ref(r Ref) *lua.LTable {
+	t := e.state.NewTable()
+	t.RawSetString("kind", lua.LString(r.Kind))
+	t.RawSetString("id", lua.LString(r.ID))
+	t.RawSetString("name", lua.LString(r.Name))
+	return t
+}
+
+func checkRef(L *lua.LState, n int) Ref {
+	t := L.CheckTable(n)
+	return Ref{
+		Kind: lua.LVAsString(t.RawGetString("kind")),
+		ID:   lua.LVAsString(t.RawGetString("id")),
+		Name: lua.LVAsString(t.RawGetString("name")),
+	}
+}
+
+// checkRoom accepts a room ref or a room id.
+func checkRoom(L *lua.LState, n int) string {
+	if t, ok := L.Get(n).(*lua.LTable); ok {
+		return lua.LVAsString(t.RawGetString("id"))
+	}
+	return L.CheckString(n)
+}
+
+func parseChunk(name, source string) ([]ast.Stmt, error) {
+	return parse.Parse(strings.NewReader(source), name)
+}
+
+// CheckWorld compiles every script of w and reports the broken ones the way world.Load reports
+// broken area files.
+func CheckWorld(w *world.World) error {
+	var problems world.ValidationError
+	for name, source := range w.Scripts {
+		if err := Check(name, source); err != nil {
+			problems = append(problems, world.Problem{File: name, Msg: err.Error()})
+		}
+	}
+	if len(problems) > 0 {
+		sort.Slice(problems, func(i, j int) bool {
+			return problems[i].File < problems[j].File
+		})
+		return problems
+	}
+	return nil
+}
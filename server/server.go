@@ -12,27 +12,47 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pborman/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
+	"github.com/zrma/mud/chat"
 	"github.com/zrma/mud/logging"
 	"github.com/zrma/mud/pb"
+	"github.com/zrma/mud/server/game"
+	"github.com/zrma/mud/server/limit"
 	"github.com/zrma/mud/server/session"
 )
 
-func New(logger logging.Logger, host string, port int) *Server {
+func New(logger logging.Logger, host string, port int, cfg game.Config) (*Server, error) {
+	g, err := game.New(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var now func() time.Time
+	if cfg.Clock != nil {
+		now = cfg.Clock.Now
+	}
 	s := Server{
 		logger:  logger,
 		port:    port,
-        host:    host,
+		host:    host,
+		game:    g,
+		limiter: limit.New(cfg.Limits, now),
 		session: make(map[string]*session.Session),
 	}
-	return &s
+	return &s, nil
 }
 
 type Server struct {
 	logger logging.Logger
 	port   int
-    host   string
+	host   string
+	game   *game.Game
+	// limiter bounds how often a session and an address may call the server.
+	limiter *limit.Limiter
 
 	server *grpc.Server
 
@@ -60,16 +80,31 @@ func (s *Server) Run() {
 		}),
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.game.Watch(ctx, watchInterval)
+	go s.game.Run(ctx)
+
 	s.server = grpc.NewServer(opts...)
 
 	pb.RegisterMudServer(s.server, s)
 	if err := s.server.Serve(server); err != nil {
 		panic("failed to serve: " + err.Error())
 	}
+	s.game.Close()
+}
+
+// Shutdown disconnects every client and makes Run return once the game is saved.
+func (s *Server) Shutdown() {
+	if s.server != nil {
+		s.server.Stop()
+	}
 }
 
 const (
 	key = "mud"
+
+	watchInterval = 2 * time.Second
 )
 
 func (s *Server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingReply, error) {
@@ -80,22 +115,63 @@ func (s *Server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingReply,
 	)
 
 	name := req.GetName()
-	token := req.GetToken()
-	if token == "" {
-		func() {
-			s.mutex.Lock()
-			defer s.mutex.Unlock()
 
-			token = uuid.New()
-			s.session[token] = session.New()
-		}()
+	// A renewal carries the previous token and keeps its session.
+	var token string
+	if req.GetToken() != "" {
+		if err := parse(req.GetToken(), func(claims jwt.MapClaims) error {
+			token, _ = claims["token"].(string)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.allow(ctx, token); err != nil {
+		return nil, err
 	}
 
+	sess := func() *session.Session {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if _, ok := s.session[token]; ok {
+			return nil
+		}
+		token = uuid.New()
+		sess := session.New()
+		s.session[token] = sess
+		return sess
+	}()
+	if sess != nil {
+		if _, err := s.game.Join(token, name, req.GetPassword(), address(ctx), sess); err != nil {
+			func() {
+				s.mutex.Lock()
+				defer s.mutex.Unlock()
+
+				delete(s.session, token)
+			}()
+			switch err {
+			case game.ErrBanned:
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			case game.ErrPassword:
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			case game.ErrAccount:
+				return nil, status.Error(codes.Unavailable, err.Error())
+			}
+			return nil, err
+		}
+	}
+
+	// The role comes from the account and is renewed with the token, so a changed role reaches
+	// the claims within a renewal.
+	r, _ := s.game.Role(token)
+
 	// Create a new token object, specifying signing method and the claims
 	// you would like it to contain.
 	newToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"name":  name,
 		"token": token,
+		"role":  r.String(),
 	})
 
 	// Sign and get the complete encoded token as a string using the secret
@@ -112,37 +188,69 @@ func (s *Server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingReply,
 }
 
 func (s *Server) Message(ctx context.Context, req *pb.MessageRequest) (*pb.MessageReply, error) {
-	token := req.GetToken()
-	msg := req.GetMsg()
-
 	s.logger.Info(
 		"receive",
 		"method", "Message",
-		"token", token,
-		"msg", msg,
+		"channel", req.GetChannel(),
+		"target", req.GetTarget(),
+		"msg", req.GetMsg(),
 	)
 
-	if err := parse(token, func(claims jwt.MapClaims) error {
-		s.logger.Info(
-			"decrypted",
-			"method", "Message",
-			"name", claims["name"],
-			"token", claims["token"],
-		)
+	var token string
+	if err := parse(req.GetToken(), func(claims jwt.MapClaims) error {
+		token, _ = claims["token"].(string)
 		return nil
 	}); err != nil {
 		return nil, err
 	}
 
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	for _, v := range s.session {
-		v.Put(msg)
+	if err := s.allow(ctx, token); err != nil {
+		return nil, err
 	}
 
+	if err := s.game.Message(token, req.GetChannel(), req.GetTarget(), req.GetMsg()); err != nil {
+		switch err {
+		case game.ErrMuted:
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		case game.ErrNoPlayer:
+			return nil, status.Error(codes.NotFound, err.Error())
+		case game.ErrIgnored:
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case chat.ErrNotJoined:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
 	return &pb.MessageReply{}, nil
 }
 
+// allow takes a request of a session out of its limits and those of the address it comes from.
+func (s *Server) allow(ctx context.Context, token string) error {
+	address := address(ctx)
+	if err := s.limiter.Allow(token, address); err != nil {
+		s.logger.Warn(
+			"request limited",
+			"token", token,
+			"address", address,
+			"err", err,
+		)
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return nil
+}
+
+// address is the IP a request comes from, or empty when it isn't known.
+func address(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
+
 func parse(token string, f func(claims jwt.MapClaims) error) error {
 	// Parse takes the token string and a function for looking up the key. The latter is especially
 	// useful if you use multiple keys for your application.  The standard is to use 'kid' in the
@@ -184,6 +292,9 @@ func (s *Server) Receive(req *pb.ReceiveRequest, stream pb.Mud_ReceiveServer) er
 	}); err != nil {
 		return err
 	}
+	if err := s.allow(stream.Context(), token); err != nil {
+		return err
+	}
 
 	sess := func() *session.Session {
 		s.mutex.Lock()
@@ -194,18 +305,112 @@ func (s *Server) Receive(req *pb.ReceiveRequest, stream pb.Mud_ReceiveServer) er
 	if sess == nil {
 		return errors.New("invalid session key")
 	}
+	defer func() {
+		func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+
+			delete(s.session, token)
+		}()
+		s.limiter.Forget(token)
+		s.game.Leave(token)
+	}()
 
 	for stream.Context().Err() == nil {
 		select {
 		case <-ticker.C:
 			for _, m := range sess.Get() {
 				if err := stream.Send(&pb.ReceiveReply{
-					Msg: m,
+					Msg:  m.Text,
+					Kind: m.Kind,
+					Data: m.Data,
 				}); err != nil {
 					return err
 				}
 			}
+			// the game closes the session of a kicked player
+			if sess.Closed() {
+				return nil
+			}
 		}
 	}
 	return nil
 }
+
+func (s *Server) Command(ctx context.Context, req *pb.CommandRequest) (*pb.CommandReply, error) {
+	line := req.GetLine()
+
+	s.logger.Info(
+		"receive",
+		"method", "Command",
+		"line", line,
+	)
+
+	var token, role string
+	if err := parse(req.GetToken(), func(claims jwt.MapClaims) error {
+		token, _ = claims["token"].(string)
+		role, _ = claims["role"].(string)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.allow(ctx, token); err != nil {
+		return nil, err
+	}
+
+	if err := s.game.Execute(token, line); err != nil {
+		switch err {
+		case game.ErrUnknownCommand:
+			return nil, status.Error(codes.NotFound, err.Error())
+		case game.ErrPermission:
+			s.logger.Warn(
+				"command refused",
+				"role", role,
+				"line", line,
+			)
+			return nil, status.Error(codes.PermissionDenied, "권한이 없습니다")
+		}
+		return nil, err
+	}
+	return &pb.CommandReply{}, nil
+}
+
+func (s *Server) History(ctx context.Context, req *pb.HistoryRequest) (*pb.HistoryReply, error) {
+	s.logger.Info(
+		"receive",
+		"method", "History",
+		"channel", req.GetChannel(),
+		"limit", req.GetLimit(),
+	)
+
+	var token string
+	if err := parse(req.GetToken(), func(claims jwt.MapClaims) error {
+		token, _ = claims["token"].(string)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.allow(ctx, token); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.game.History(token, req.GetChannel(), int(req.GetLimit()))
+	if err != nil {
+		if err == chat.ErrNotJoined {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+	reply := &pb.HistoryReply{}
+	for _, e := range entries {
+		reply.Entries = append(reply.Entries, &pb.HistoryEntry{
+			Time:    e.Time.UnixNano() / int64(time.Millisecond),
+			Channel: e.Channel,
+			Sender:  e.Sender,
+			Msg:     e.Text,
+		})
+	}
+	return reply, nil
+}
@@ -9,17 +9,32 @@ func New() *Session {
 type Session struct {
 	sync.Mutex
 
-	msg []string
+	msg    []Message
+	closed bool
+}
+
+// Message is either plain text, an event for rich clients, or both.
+type Message struct {
+	Text string
+	Kind string
+	Data []byte
 }
 
 func (s *Session) Put(msg string) {
 	s.Lock()
 	defer s.Unlock()
 
-	s.msg = append(s.msg, msg)
+	s.msg = append(s.msg, Message{Text: msg})
 }
 
-func (s *Session) Get() []string {
+func (s *Session) PutEvent(kind string, data []byte) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.msg = append(s.msg, Message{Kind: kind, Data: data})
+}
+
+func (s *Session) Get() []Message {
 	s.Lock()
 	defer s.Unlock()
 
@@ -27,8 +42,24 @@ func (s *Session) Get() []string {
 		return nil
 	}
 
-	msg := s.msg[:]
-	s.msg = s.msg[:0]
+	msg := s.msg
+	s.msg = nil
 
 	return msg
 }
+
+// Close ends the session from the server side, like when a player is kicked. Messages put before
+// are still delivered.
+func (s *Session) Close() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.closed = true
+}
+
+func (s *Session) Closed() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.closed
+}
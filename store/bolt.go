@@ -0,0 +1,135 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	accountsBucket = []byte("accounts")
+	playersBucket  = []byte("players")
+	worldBucket    = []byte("world")
+	worldKey       = []byte("world")
+
+	buckets = map[string][]byte{
+		KindAccount: accountsBucket,
+		KindPlayer:  playersBucket,
+		KindWorld:   worldBucket,
+	}
+)
+
+// Bolt keeps the records as JSON in a single BoltDB file. Every save is a transaction.
+type Bolt struct {
+	db *bolt.DB
+}
+
+func NewBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{accountsBucket, playersBucket, worldBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Bolt{db: db}, nil
+}
+
+func (b *Bolt) LoadAccount(name string) (*Account, error) {
+	var a Account
+	if err := b.get(KindAccount, []byte(name), &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (b *Bolt) SaveAccount(a *Account) error {
+	a.Version = Version
+	return b.put(accountsBucket, []byte(a.Name), a)
+}
+
+func (b *Bolt) LoadPlayer(name string) (*Player, error) {
+	var p Player
+	if err := b.get(KindPlayer, []byte(name), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (b *Bolt) SavePlayer(p *Player) error {
+	p.Version = Version
+	return b.put(playersBucket, []byte(p.Name), p)
+}
+
+func (b *Bolt) LoadWorld() (*World, error) {
+	var w World
+	if err := b.get(KindWorld, worldKey, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (b *Bolt) SaveWorld(w *World) error {
+	w.Version = Version
+	return b.put(worldBucket, worldKey, w)
+}
+
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
+
+func (b *Bolt) Records(kind string, f func(name string, data []byte) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(buckets[kind]).ForEach(func(k, v []byte) error {
+			name := string(k)
+			if kind == KindWorld {
+				name = ""
+			}
+			return f(name, v)
+		})
+	})
+}
+
+func (b *Bolt) Replace(kind, name string, data []byte) error {
+	key := []byte(name)
+	if kind == KindWorld {
+		key = worldKey
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(buckets[kind]).Put(key, data)
+	})
+}
+
+func (b *Bolt) get(kind string, key []byte, v interface{}) error {
+	var data []byte
+	if err := b.db.View(func(tx *bolt.Tx) error {
+		// The value is only valid during the transaction.
+		data = append(data, tx.Bucket(buckets[kind]).Get(key)...)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if data == nil {
+		return ErrNotFound
+	}
+	return decode(kind, data, v)
+}
+
+func (b *Bolt) put(bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, data)
+	})
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	accountsDir = "accounts"
+	playersDir  = "players"
+	worldFile   = "world.json"
+	ext         = ".json"
+)
+
+// File keeps every record in its own JSON file below a directory, which makes saves easy to
+// inspect and fix by hand. Files are replaced by writing a temporary file and renaming it.
+type File struct {
+	dir string
+}
+
+func NewFile(dir string) (*File, error) {
+	for _, sub := range []string{accountsDir, playersDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &File{dir: dir}, nil
+}
+
+func (f *File) LoadAccount(name string) (*Account, error) {
+	var a Account
+	if err := readJSON(KindAccount, f.path(accountsDir, name), &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (f *File) SaveAccount(a *Account) error {
+	a.Version = Version
+	return writeJSON(f.path(accountsDir, a.Name), a)
+}
+
+func (f *File) LoadPlayer(name string) (*Player, error) {
+	var p Player
+	if err := readJSON(KindPlayer, f.path(playersDir, name), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (f *File) SavePlayer(p *Player) error {
+	p.Version = Version
+	return writeJSON(f.path(playersDir, p.Name), p)
+}
+
+func (f *File) LoadWorld() (*World, error) {
+	var w World
+	if err := readJSON(KindWorld, filepath.Join(f.dir, worldFile), &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (f *File) SaveWorld(w *World) error {
+	w.Version = Version
+	return writeJSON(filepath.Join(f.dir, worldFile), w)
+}
+
+func (f *File) Records(kind string, fn func(name string, data []byte) error) error {
+	if kind == KindWorld {
+		b, err := ioutil.ReadFile(filepath.Join(f.dir, worldFile))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn("", b)
+	}
+
+	dir := filepath.Join(f.dir, f.sub(kind))
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ext {
+			continue
+		}
+		name, err := url.PathUnescape(strings.TrimSuffix(file.Name(), ext))
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return err
+		}
+		if err := fn(name, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *File) Replace(kind, name string, data []byte) error {
+	if kind == KindWorld {
+		return writeJSON(filepath.Join(f.dir, worldFile), json.RawMessage(data))
+	}
+	return writeJSON(f.path(f.sub(kind), name), json.RawMessage(data))
+}
+
+func (f *File) sub(kind string) string {
+	if kind == KindAccount {
+		return accountsDir
+	}
+	return playersDir
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+// path escapes the name, so that no name can point outside of its directory.
+func (f *File) path(sub, name string) string {
+	return filepath.Join(f.dir, sub, url.PathEscape(name)+ext)
+}
+
+func readJSON(kind, path string, v interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return decode(kind, b, v)
+}
+
+// writeJSON replaces the file at path in a way that survives a crash at any point: the data is
+// synced to a temporary file in the same directory before it is renamed over the old one.
+func writeJSON(path string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Version is the format records are saved in. Records saved before versions existed count as
+// version 1.
+const Version = 3
+
+// Kinds of records.
+const (
+	KindAccount = "account"
+	KindPlayer  = "player"
+	KindWorld   = "world"
+)
+
+var Kinds = []string{KindAccount, KindPlayer, KindWorld}
+
+// Migration upgrades a decoded record by one version in place.
+type Migration func(record map[string]interface{}) error
+
+// migrations[kind][from] upgrades a record of kind from version from to from+1.
+var migrations = make(map[string]map[int]Migration)
+
+// Register adds the migration of a kind of record from version from to the next one. Every
+// version below Version needs one for every kind.
+func Register(kind string, from int, m Migration) {
+	if migrations[kind] == nil {
+		migrations[kind] = make(map[int]Migration)
+	}
+	if _, ok := migrations[kind][from]; ok {
+		panic(fmt.Sprintf("store: migration of %s from version %d registered twice", kind, from))
+	}
+	migrations[kind][from] = m
+}
+
+func init() {
+	// Version 2 only starts writing the version down.
+	for _, kind := range Kinds {
+		Register(kind, 1, func(record map[string]interface{}) error { return nil })
+	}
+
+	// Version 3 replaces the admin flag of accounts with a role.
+	Register(KindAccount, 2, func(record map[string]interface{}) error {
+		if admin, _ := record["admin"].(bool); admin {
+			record["role"] = "admin"
+		}
+		delete(record, "admin")
+		return nil
+	})
+	Register(KindPlayer, 2, func(record map[string]interface{}) error { return nil })
+	Register(KindWorld, 2, func(record map[string]interface{}) error { return nil })
+}
+
+// upgrade brings a saved record up to Version and returns it with the version it was saved in.
+// Records of a newer version are refused rather than loaded with fields silently dropped.
+func upgrade(kind string, data []byte) ([]byte, int, error) {
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, 0, err
+	}
+
+	from := 1
+	if v, ok := record["version"].(float64); ok {
+		from = int(v)
+	}
+	switch {
+	case from == Version:
+		return data, from, nil
+	case from > Version:
+		return nil, from, fmt.Errorf("%s saved in version %d, newer than %d", kind, from, Version)
+	}
+
+	for v := from; v < Version; v++ {
+		m, ok := migrations[kind][v]
+		if !ok {
+			return nil, from, fmt.Errorf("%s: no migration from version %d", kind, v)
+		}
+		if err := m(record); err != nil {
+			return nil, from, fmt.Errorf("%s: migration from version %d: %v", kind, v, err)
+		}
+		record["version"] = v + 1
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, from, err
+	}
+	return data, from, nil
+}
+
+// decode reads a record of any known version into v.
+func decode(kind string, data []byte, v interface{}) error {
+	data, _, err := upgrade(kind, data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Raw gives access to the records as they are saved, for migrating them in place.
+type Raw interface {
+	// Records calls f with every saved record of a kind, in no particular order. The data is only
+	// valid during the call, and f must not call Replace.
+	Records(kind string, f func(name string, data []byte) error) error
+	Replace(kind, name string, data []byte) error
+}
+
+// Change is what migrating one record does.
+type Change struct {
+	Kind string
+	Name string
+	From int
+	// Fields are the paths of the fields that were added, removed or changed.
+	Fields []string
+}
+
+func (c Change) String() string {
+	name := c.Kind
+	if c.Name != "" {
+		name += " " + c.Name
+	}
+	return fmt.Sprintf("%s: version %d -> %d %v", name, c.From, Version, c.Fields)
+}
+
+// Migrate upgrades every outdated record to Version and reports what changed. With dryRun it
+// only reports and leaves the records alone.
+func Migrate(r Raw, dryRun bool) ([]Change, error) {
+	var changes []Change
+	for _, kind := range Kinds {
+		// BoltDB holds a read transaction during Records, which a write waits for when it needs
+		// to grow the file, so the records are replaced after reading them all.
+		upgrades := make(map[string][]byte)
+		if err := r.Records(kind, func(name string, data []byte) error {
+			upgraded, from, err := upgrade(kind, data)
+			if err != nil {
+				if name != "" {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+				return err
+			}
+			if from == Version {
+				return nil
+			}
+
+			fields, err := diff(data, upgraded)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, Change{Kind: kind, Name: name, From: from, Fields: fields})
+			upgrades[name] = upgraded
+			return nil
+		}); err != nil {
+			return changes, err
+		}
+		if dryRun {
+			continue
+		}
+		for name, upgraded := range upgrades {
+			if err := r.Replace(kind, name, upgraded); err != nil {
+				return changes, err
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+func diff(before, after []byte) ([]string, error) {
+	var a, b interface{}
+	if err := json.Unmarshal(before, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &b); err != nil {
+		return nil, err
+	}
+	var fields []string
+	diffValue("", a, b, &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func diffValue(path string, a, b interface{}, fields *[]string) {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		if !reflect.DeepEqual(a, b) {
+			*fields = append(*fields, "~"+path)
+		}
+		return
+	}
+
+	for key, av := range am {
+		bv, ok := bm[key]
+		if !ok {
+			*fields = append(*fields, "-"+join(path, key))
+			continue
+		}
+		diffValue(join(path, key), av, bv, fields)
+	}
+	for key := range bm {
+		if _, ok := am[key]; !ok {
+			*fields = append(*fields, "+"+join(path, key))
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
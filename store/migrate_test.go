@@ -0,0 +1,235 @@
+package store_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zrma/mud/store"
+)
+
+// The fixtures below testdata hold the same records saved in every version.
+var versions = []string{"v1", "v2", "v3"}
+
+// copyFixture copies a fixture directory to a temporary one, so that migrating doesn't change it.
+// The caller removes the copy.
+func copyFixture(t *testing.T, version string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "store-"+version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join("testdata", version)
+	if err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0755)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, rel), b, 0644)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// records reads every record of a store, decoded so that the order of fields doesn't matter.
+func records(t *testing.T, r store.Raw) map[string]interface{} {
+	t.Helper()
+	all := make(map[string]interface{})
+	for _, kind := range store.Kinds {
+		if err := r.Records(kind, func(name string, data []byte) error {
+			var v interface{}
+			if err := json.Unmarshal(data, &v); err != nil {
+				return err
+			}
+			all[kind+" "+name] = v
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return all
+}
+
+func open(t *testing.T, dir string) *store.File {
+	t.Helper()
+	f, err := store.NewFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestMigrate(t *testing.T) {
+	want := records(t, open(t, filepath.Join("testdata", "v3")))
+
+	for _, version := range versions {
+		t.Run(version, func(t *testing.T) {
+			dir := copyFixture(t, version)
+			defer os.RemoveAll(dir)
+			f := open(t, dir)
+			before := records(t, f)
+
+			if _, err := store.Migrate(f, false); err != nil {
+				t.Fatal(err)
+			}
+			// older fixtures miss some records, like the admin bob from before there were admins
+			got := records(t, f)
+			if len(got) != len(before) {
+				t.Errorf("%d records after migrating, %d before", len(got), len(before))
+			}
+			for key, record := range got {
+				if !reflect.DeepEqual(record, want[key]) {
+					t.Errorf("%s = %v, want %v", key, record, want[key])
+				}
+			}
+
+			changes, err := store.Migrate(f, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != 0 {
+				t.Errorf("migrating again changed %v", changes)
+			}
+		})
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	for i, version := range versions[:len(versions)-1] {
+		from := i + 1
+		t.Run(version, func(t *testing.T) {
+			dir := copyFixture(t, version)
+			defer os.RemoveAll(dir)
+			f := open(t, dir)
+			before := records(t, f)
+
+			changes, err := store.Migrate(f, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != len(before) {
+				t.Errorf("%d changes, want one for every of the %d records", len(changes), len(before))
+			}
+			for _, c := range changes {
+				if c.From != from {
+					t.Errorf("%v: from version %d, want %d", c, c.From, from)
+				}
+			}
+			if after := records(t, f); !reflect.DeepEqual(after, before) {
+				t.Errorf("dry run changed the records")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, version := range versions {
+		t.Run(version, func(t *testing.T) {
+			f := open(t, filepath.Join("testdata", version))
+			a, err := f.LoadAccount("alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Name != "alice" {
+				t.Errorf("account name = %q, want alice", a.Name)
+			}
+			p, err := f.LoadPlayer("alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Room != "town-north-road" || len(p.Inventory.Items) != 1 {
+				t.Errorf("player = %+v, want alice with a stick on the north road", p)
+			}
+			w, err := f.LoadWorld()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(w.Floor["town-inn"]) != 2 {
+				t.Errorf("%d items in the inn, want 2", len(w.Floor["town-inn"]))
+			}
+		})
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	dir := copyFixture(t, "v3")
+	defer os.RemoveAll(dir)
+	newer := fmt.Sprintf(`{"version": %d, "name": "carol"}`, store.Version+1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "accounts", "carol.json"), []byte(newer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := open(t, dir)
+
+	if _, err := f.LoadAccount("carol"); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("loading a newer account: err = %v, want it refused", err)
+	}
+	if _, err := store.Migrate(f, false); err == nil {
+		t.Errorf("migrating a newer account succeeded, want it refused")
+	}
+}
+
+// TestMigrateBolt migrates enough records for BoltDB to grow its file while migrating.
+func TestMigrateBolt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store-bolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b, err := store.NewBolt(filepath.Join(dir, "mud.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	v1, err := ioutil.ReadFile(filepath.Join("testdata", "v1", "players", "alice.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 200
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("player%d", i)
+		data := strings.Replace(string(v1), `"alice"`, `"`+name+`"`, -1)
+		if err := b.Replace(store.KindPlayer, name, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := store.Migrate(b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != n {
+		t.Errorf("%d changes, want %d", len(changes), n)
+	}
+	if err := b.Records(store.KindPlayer, func(name string, data []byte) error {
+		var record struct {
+			Version int `json:"version"`
+		}
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		if record.Version != store.Version {
+			t.Errorf("%s in version %d after migrating", name, record.Version)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// passwordIterations makes guessing a password from a stolen hash slow. Logins check it while
+// holding the game lock, so it is kept to a few milliseconds.
+const passwordIterations = 20000
+
+var ErrEmptyPassword = errors.New("empty password")
+
+// HashPassword salts and hashes a password with PBKDF2-HMAC-SHA256. The result holds the
+// iterations and the salt, so that CheckPassword still works when they change.
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", ErrEmptyPassword
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2([]byte(password), salt, passwordIterations)
+	return strings.Join([]string{
+		"pbkdf2-sha256",
+		strconv.Itoa(passwordIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// CheckPassword tells whether password is the one hash was made from.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" || password == "" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(pbkdf2([]byte(password), salt, iterations), key) == 1
+}
+
+// pbkdf2 derives a single block of PBKDF2 (RFC 8018), which is all a SHA-256 sized key needs.
+func pbkdf2(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	var block [4]byte
+	binary.BigEndian.PutUint32(block[:], 1)
+	mac.Write(block[:])
+	u := mac.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
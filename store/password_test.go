@@ -0,0 +1,68 @@
+package store_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"github.com/zrma/mud/store"
+)
+
+func TestPassword(t *testing.T) {
+	hash, err := store.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !store.CheckPassword(hash, "secret") {
+		t.Error("the right password was refused")
+	}
+	for _, wrong := range []string{"", "Secret", "secret "} {
+		if store.CheckPassword(hash, wrong) {
+			t.Errorf("the wrong password %q was accepted", wrong)
+		}
+	}
+
+	other, err := store.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("two hashes of the same password are equal, want them salted")
+	}
+	if _, err := store.HashPassword(""); err != store.ErrEmptyPassword {
+		t.Errorf("hashing an empty password: err = %v, want ErrEmptyPassword", err)
+	}
+	if store.CheckPassword("", "") || store.CheckPassword("plain", "plain") {
+		t.Error("a malformed hash accepted a password")
+	}
+}
+
+// TestPasswordVectors checks the key derivation against published PBKDF2-HMAC-SHA256 vectors,
+// cut to the single block a hash keeps.
+func TestPasswordVectors(t *testing.T) {
+	vectors := []struct {
+		password, salt string
+		iterations     int
+		key            string
+	}{
+		{"password", "salt", 1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		// RFC 7914, section 11
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+	}
+	for _, v := range vectors {
+		key, err := hex.DecodeString(v.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := "pbkdf2-sha256$" + strconv.Itoa(v.iterations) + "$" +
+			base64.RawStdEncoding.EncodeToString([]byte(v.salt)) + "$" +
+			base64.RawStdEncoding.EncodeToString(key)
+		if !store.CheckPassword(hash, v.password) {
+			t.Errorf("%q with %q, %d iterations: the derived key isn't %s",
+				v.password, v.salt, v.iterations, v.key)
+		}
+	}
+}
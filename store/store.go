@@ -0,0 +1,102 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zrma/mud/character"
+	"github.com/zrma/mud/item"
+	"github.com/zrma/mud/role"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Store keeps what has to survive a restart. Implementations make every save atomic: after a
+// crash a record is either the old or the new version, never a mix. Records are saved in the
+// current Version and upgraded on load when they are older.
+type Store interface {
+	LoadAccount(name string) (*Account, error)
+	SaveAccount(a *Account) error
+	LoadPlayer(name string) (*Player, error)
+	SavePlayer(p *Player) error
+	LoadWorld() (*World, error)
+	SaveWorld(w *World) error
+	Close() error
+}
+
+// Account is a login. Its name is also the name of its only character for now.
+type Account struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Created   time.Time `json:"created"`
+	LastLogin time.Time `json:"last_login"`
+	// Role tells which commands the account may use. It only counts when the account has a
+	// password, a name alone never logs in as more than a player.
+	Role role.Role `json:"role,omitempty"`
+	// Password is made by HashPassword. Accounts without one take the first password given at
+	// login, unless their role is above player: those get one with "mud password".
+	Password string `json:"password,omitempty"`
+	// MutedUntil is when a muted account may talk again.
+	MutedUntil *time.Time `json:"muted_until,omitempty"`
+}
+
+// Player is a character with what it carries and where it stands.
+type Player struct {
+	Version   int                  `json:"version"`
+	Name      string               `json:"name"`
+	Room      string               `json:"room"`
+	Character *character.Character `json:"character"`
+	Inventory *item.Inventory      `json:"inventory"`
+	Saved     time.Time            `json:"saved"`
+}
+
+// World is the state of the world that changes while the game runs, like the items lying in
+// rooms and containers, and who is banned from it.
+type World struct {
+	Version int                     `json:"version"`
+	Floor   map[string][]*item.Item `json:"floor"`
+	Bans    *Bans                   `json:"bans,omitempty"`
+	Saved   time.Time               `json:"saved"`
+}
+
+// Bans are the accounts and the addresses that may not connect, by account name and by IP.
+type Bans struct {
+	Accounts  map[string]*Ban `json:"accounts,omitempty"`
+	Addresses map[string]*Ban `json:"addresses,omitempty"`
+}
+
+func NewBans() *Bans {
+	return &Bans{
+		Accounts:  make(map[string]*Ban),
+		Addresses: make(map[string]*Ban),
+	}
+}
+
+// Ban tells who banned an account or address, when and why.
+type Ban struct {
+	By     string    `json:"by"`
+	Reason string    `json:"reason,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Open picks an implementation by the prefix of spec: "file:<dir>" or "bolt:<file>". Both of
+// them implement Raw as well.
+func Open(spec string) (Store, error) {
+	kind, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+	if arg == "" {
+		return nil, fmt.Errorf("store %q: missing path", spec)
+	}
+
+	switch kind {
+	case "file":
+		return NewFile(arg)
+	case "bolt":
+		return NewBolt(arg)
+	}
+	return nil, fmt.Errorf("store %q: unknown kind %s", spec, kind)
+}
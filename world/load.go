@@ -0,0 +1,480 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ext       = ".json"
+	scriptExt = ".lua"
+)
+
+type Problem struct {
+	File string
+	ID   string
+	Msg  string
+}
+
+func (p Problem) String() string {
+	if p.ID == "" {
+		return fmt.Sprintf("%s: %s", p.File, p.Msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.File, p.ID, p.Msg)
+}
+
+// ValidationError lists every problem found in the area files, not only the first one, so that
+// builders can fix them in one go.
+type ValidationError []Problem
+
+func (e ValidationError) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, p := range e {
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e ValidationError) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		if e[i].File != e[j].File {
+			return e[i].File < e[j].File
+		}
+		if e[i].ID != e[j].ID {
+			return e[i].ID < e[j].ID
+		}
+		return e[i].Msg < e[j].Msg
+	})
+}
+
+// Load parses every area file in dir and validates the world they build together.
+func Load(dir string) (*World, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var problems ValidationError
+	var areas []*Area
+	for _, file := range files {
+		area, err := LoadArea(file)
+		if err != nil {
+			problems = append(problems, Problem{File: file, Msg: err.Error()})
+			continue
+		}
+		areas = append(areas, area)
+	}
+	if len(files) == 0 {
+		problems = append(problems, Problem{File: dir, Msg: "no area files"})
+	}
+
+	w, more := build(areas)
+	problems = append(problems, more...)
+	problems = append(problems, loadScripts(dir, w)...)
+	if len(problems) > 0 {
+		problems.sort()
+		return nil, problems
+	}
+	return w, nil
+}
+
+func LoadArea(file string) (*Area, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+
+	var area Area
+	if err := decoder.Decode(&area); err != nil {
+		if se, ok := err.(*json.SyntaxError); ok {
+			line := bytes.Count(b[:se.Offset], []byte("\n")) + 1
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		return nil, err
+	}
+	area.file = file
+	return &area, nil
+}
+
+// loadScripts reads the script of every room, item and mob. Paths must stay inside dir.
+func loadScripts(dir string, w *World) ValidationError {
+	var problems ValidationError
+	w.Scripts = make(map[string]string)
+
+	load := func(area *Area, id, path string) {
+		if path == "" {
+			return
+		}
+		if _, ok := w.Scripts[path]; ok {
+			return
+		}
+		clean := filepath.Clean(path)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			problems = append(problems, Problem{File: area.file, ID: id, Msg: "script outside of the area directory: " + path})
+			return
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, clean))
+		if err != nil {
+			problems = append(problems, Problem{File: area.file, ID: id, Msg: err.Error()})
+			return
+		}
+		w.Scripts[path] = string(b)
+	}
+
+	for _, area := range w.Areas {
+		for _, r := range area.Rooms {
+			load(area, r.ID, r.Script)
+		}
+		for _, t := range area.Items {
+			load(area, t.ID, t.Script)
+		}
+		for _, t := range area.Mobs {
+			load(area, t.ID, t.Script)
+		}
+	}
+	return problems
+}
+
+func build(areas []*Area) (*World, ValidationError) {
+	w := &World{
+		Areas:   make(map[string]*Area),
+		Rooms:   make(map[string]*Room),
+		Items:   make(map[string]*ItemTemplate),
+		Mobs:    make(map[string]*MobTemplate),
+		Quests:  make(map[string]*Quest),
+		Socials: make(map[string]*Social),
+	}
+
+	var problems ValidationError
+	report := func(file, id, format string, args ...interface{}) {
+		problems = append(problems, Problem{File: file, ID: id, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	files := make(map[string]string)
+	for _, area := range areas {
+		if area.ID == "" {
+			report(area.file, "", "area without id")
+			continue
+		}
+		if other, ok := w.Areas[area.ID]; ok {
+			report(area.file, area.ID, "duplicate area id, also in %s", other.file)
+			continue
+		}
+		w.Areas[area.ID] = area
+		dropNull(area, func(what string) {
+			report(area.file, "", "null %s", what)
+		})
+
+		for _, r := range area.Rooms {
+			r.Area = area.ID
+			if r.ID == "" {
+				report(area.file, "", "room without id")
+				continue
+			}
+			if other, ok := files[r.ID]; ok {
+				report(area.file, r.ID, "duplicate id, also in %s", other)
+				continue
+			}
+			files[r.ID] = area.file
+			w.Rooms[r.ID] = r
+		}
+		for _, t := range area.Items {
+			t.Area = area.ID
+			if t.ID == "" {
+				report(area.file, "", "item without id")
+				continue
+			}
+			if other, ok := files[t.ID]; ok {
+				report(area.file, t.ID, "duplicate id, also in %s", other)
+				continue
+			}
+			files[t.ID] = area.file
+			w.Items[t.ID] = t
+
+			if t.Slot != "" && !validSlot(t.Slot) {
+				report(area.file, t.ID, "unknown slot %s", t.Slot)
+			}
+			if t.Capacity > 0 && !t.Container {
+				report(area.file, t.ID, "capacity without container")
+			}
+			if t.Damage < 0 || t.Armor < 0 || t.Value < 0 {
+				report(area.file, t.ID, "negative damage, armor or value")
+			}
+		}
+		for _, t := range area.Mobs {
+			t.Area = area.ID
+			if t.ID == "" {
+				report(area.file, "", "mob without id")
+				continue
+			}
+			if other, ok := files[t.ID]; ok {
+				report(area.file, t.ID, "duplicate id, also in %s", other)
+				continue
+			}
+			files[t.ID] = area.file
+			w.Mobs[t.ID] = t
+
+			if t.Level < 0 || t.Damage < 0 || t.Armor < 0 || t.Respawn < 0 {
+				report(area.file, t.ID, "negative level, damage, armor or respawn")
+			}
+			for _, b := range t.Behaviors {
+				if !validBehavior(b) {
+					report(area.file, t.ID, "unknown behavior %s", b)
+				}
+			}
+			if len(t.Shop) > 0 && !t.Has(BehaviorShopkeeper) {
+				report(area.file, t.ID, "shop without shopkeeper behavior")
+			}
+		}
+
+		for _, q := range area.Quests {
+			q.Area = area.ID
+			if q.ID == "" {
+				report(area.file, "", "quest without id")
+				continue
+			}
+			if other, ok := files[q.ID]; ok {
+				report(area.file, q.ID, "duplicate id, also in %s", other)
+				continue
+			}
+			files[q.ID] = area.file
+			w.Quests[q.ID] = q
+		}
+
+		for _, social := range area.Socials {
+			social.Area = area.ID
+			if social.Word == "" || strings.ContainsAny(social.Word, " \t") {
+				report(area.file, social.Word, "social without a single word")
+				continue
+			}
+			if other, ok := w.Socials[social.Word]; ok {
+				report(area.file, social.Word, "duplicate social, also in %s", w.Areas[other.Area].file)
+				continue
+			}
+			w.Socials[social.Word] = social
+			checkSocial(social, func(format string, args ...interface{}) {
+				report(area.file, social.Word, format, args...)
+			})
+		}
+
+		if area.Start != "" {
+			if w.Start != "" {
+				report(area.file, area.ID, "start room already set to %s", w.Start)
+			} else {
+				w.Start = area.Start
+			}
+		}
+	}
+
+	for _, area := range areas {
+		if w.Areas[area.ID] != area {
+			continue
+		}
+		for _, r := range area.Rooms {
+			if r.Name == "" {
+				report(area.file, r.ID, "room without name")
+			}
+			for dir, to := range r.Exits {
+				if _, ok := w.Rooms[to]; !ok {
+					report(area.file, r.ID, "exit %s leads to unknown room %s", dir, to)
+				}
+			}
+			for _, id := range r.Items {
+				if _, ok := w.Items[id]; !ok {
+					report(area.file, r.ID, "unknown item %s", id)
+				}
+			}
+			for _, id := range r.Mobs {
+				if _, ok := w.Mobs[id]; !ok {
+					report(area.file, r.ID, "unknown mob %s", id)
+				}
+			}
+		}
+		for _, t := range area.Mobs {
+			if w.Mobs[t.ID] != t {
+				continue
+			}
+			for _, id := range t.Shop {
+				if _, ok := w.Items[id]; !ok {
+					report(area.file, t.ID, "shop sells unknown item %s", id)
+				}
+			}
+		}
+		for _, q := range area.Quests {
+			if w.Quests[q.ID] == q {
+				checkQuest(w, q, func(format string, args ...interface{}) {
+					report(area.file, q.ID, format, args...)
+				})
+			}
+		}
+	}
+
+	if w.Start == "" {
+		problems = append(problems, Problem{File: "world", Msg: "no start room"})
+	} else if _, ok := w.Rooms[w.Start]; !ok {
+		problems = append(problems, Problem{File: "world", ID: w.Start, Msg: "start room does not exist"})
+	}
+
+	return w, problems
+}
+
+// dropNull removes the entries an area file lists as null, after reporting them, so that the rest
+// of the validation can look at every entry.
+func dropNull(area *Area, report func(what string)) {
+	rooms := area.Rooms[:0]
+	for _, r := range area.Rooms {
+		if r == nil {
+			report("room")
+			continue
+		}
+		rooms = append(rooms, r)
+	}
+	area.Rooms = rooms
+
+	items := area.Items[:0]
+	for _, t := range area.Items {
+		if t == nil {
+			report("item")
+			continue
+		}
+		items = append(items, t)
+	}
+	area.Items = items
+
+	mobs := area.Mobs[:0]
+	for _, t := range area.Mobs {
+		if t == nil {
+			report("mob")
+			continue
+		}
+		mobs = append(mobs, t)
+	}
+	area.Mobs = mobs
+
+	quests := area.Quests[:0]
+	for _, q := range area.Quests {
+		if q == nil {
+			report("quest")
+			continue
+		}
+		quests = append(quests, q)
+	}
+	area.Quests = quests
+
+	socials := area.Socials[:0]
+	for _, social := range area.Socials {
+		if social == nil {
+			report("social")
+			continue
+		}
+		socials = append(socials, social)
+	}
+	area.Socials = socials
+}
+
+func validSlot(slot string) bool {
+	for _, s := range Slots {
+		if s == slot {
+			return true
+		}
+	}
+	return false
+}
+
+func checkQuest(w *World, q *Quest, report func(format string, args ...interface{})) {
+	if q.Name == "" {
+		report("quest without name")
+	}
+	if q.Giver != "" {
+		if _, ok := w.Mobs[q.Giver]; !ok {
+			report("unknown giver %s", q.Giver)
+		}
+	}
+	for _, id := range q.Requires {
+		if _, ok := w.Quests[id]; !ok {
+			report("requires unknown quest %s", id)
+		}
+	}
+	if len(q.Steps) == 0 {
+		report("quest without steps")
+	}
+	for i, s := range q.Steps {
+		if s == nil {
+			report("step %d: null", i+1)
+			continue
+		}
+		var ok bool
+		switch s.Kind {
+		case StepKill, StepTalk:
+			_, ok = w.Mobs[s.Target]
+		case StepFetch:
+			_, ok = w.Items[s.Target]
+		case StepReach:
+			_, ok = w.Rooms[s.Target]
+		default:
+			report("step %d: unknown kind %s", i+1, s.Kind)
+			continue
+		}
+		if !ok {
+			report("step %d: unknown %s target %s", i+1, s.Kind, s.Target)
+		}
+		if s.Count < 0 {
+			report("step %d: negative count", i+1)
+		}
+	}
+	if q.Reward.Exp < 0 || q.Reward.Gold < 0 {
+		report("negative reward")
+	}
+	for _, id := range q.Reward.Items {
+		if _, ok := w.Items[id]; !ok {
+			report("rewards unknown item %s", id)
+		}
+	}
+}
+
+var placeholder = regexp.MustCompile(`\{([^{}:]{2,})(?::[^{}]*)?\}`)
+
+func checkSocial(s *Social, report func(format string, args ...interface{})) {
+	check := func(name, text string, target bool) {
+		for _, m := range placeholder.FindAllStringSubmatch(text, -1) {
+			switch {
+			case m[1] == "actor":
+			case m[1] == "target" && target:
+			case strings.HasPrefix(m[1], "#"):
+				// an arbitrary color
+			default:
+				report("%s: unknown placeholder %s", name, m[0])
+			}
+		}
+	}
+
+	if s.Alone.Actor == "" || s.Alone.Room == "" {
+		report("alone: missing actor or room message")
+	}
+	check("alone", s.Alone.Actor+s.Alone.Room, false)
+	if t := s.Targeted; t != nil {
+		if t.Actor == "" || t.Target == "" || t.Room == "" {
+			report("targeted: missing actor, target or room message")
+		}
+		check("targeted", t.Actor+t.Target+t.Room, true)
+	}
+}
+
+func validBehavior(behavior string) bool {
+	for _, b := range Behaviors {
+		if b == behavior {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package world
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watch polls the area files and scripts in dir and calls f whenever a file is added, removed or
+// modified. Editors often write a file in several steps, so f is called only once the directory
+// has been quiet for a whole interval.
+func Watch(ctx context.Context, dir string, interval time.Duration, f func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := snapshot(dir)
+	pending := false
+	for {
+		select {
+		case <-ticker.C:
+			current := snapshot(dir)
+			if !equal(last, current) {
+				last = current
+				pending = true
+				continue
+			}
+			if pending {
+				pending = false
+				f()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// snapshot collects the area files in dir and the scripts anywhere below it.
+func snapshot(dir string) map[string]time.Time {
+	result := make(map[string]time.Time)
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ext:
+			if filepath.Dir(path) == filepath.Clean(dir) {
+				result[path] = info.ModTime()
+			}
+		case scriptExt:
+			result[path] = info.ModTime()
+		}
+		return nil
+	})
+	return result
+}
+
+func equal(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !w.Equal(v) {
+			return false
+		}
+	}
+	return true
+}
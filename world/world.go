@@ -0,0 +1,195 @@
+package world
+
+// Area is the content of a single area file. IDs are global, so rooms of one area can link to
+// rooms of another.
+type Area struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Start   string          `json:"start,omitempty"`
+	Rooms   []*Room         `json:"rooms"`
+	Items   []*ItemTemplate `json:"items,omitempty"`
+	Mobs    []*MobTemplate  `json:"mobs,omitempty"`
+	Quests  []*Quest        `json:"quests,omitempty"`
+	Socials []*Social       `json:"socials,omitempty"`
+
+	file string
+}
+
+type Room struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Exits       map[string]string `json:"exits,omitempty"`
+	Items       []string          `json:"items,omitempty"`
+	Mobs        []string          `json:"mobs,omitempty"`
+	// Outdoor rooms see the weather.
+	Outdoor bool `json:"outdoor,omitempty"`
+	// Script is the path of a Lua file, relative to the area directory.
+	Script string `json:"script,omitempty"`
+
+	Area string `json:"-"`
+}
+
+// Equipment slots. An item with a slot can be worn, or wielded for SlotWeapon.
+const (
+	SlotWeapon = "무기"
+	SlotHead   = "머리"
+	SlotBody   = "몸"
+	SlotHands  = "손"
+	SlotFeet   = "발"
+)
+
+var Slots = []string{SlotWeapon, SlotHead, SlotBody, SlotHands, SlotFeet}
+
+type ItemTemplate struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Description string   `json:"description"`
+	Slot        string   `json:"slot,omitempty"`
+	Container   bool     `json:"container,omitempty"`
+	Capacity    int      `json:"capacity,omitempty"`
+	Fixed       bool     `json:"fixed,omitempty"`
+	Damage      int      `json:"damage,omitempty"`
+	Armor       int      `json:"armor,omitempty"`
+	// Value is the price at a shop. Shops buy back for half of it.
+	Value  int    `json:"value,omitempty"`
+	Script string `json:"script,omitempty"`
+
+	Area string `json:"-"`
+}
+
+// Behaviors of mobs.
+const (
+	// BehaviorWander walks around the area of the mob.
+	BehaviorWander = "wander"
+	// BehaviorAggressive attacks players on sight.
+	BehaviorAggressive = "aggressive"
+	// BehaviorGuard stays at home and helps players fighting in its room.
+	BehaviorGuard = "guard"
+	// BehaviorShopkeeper sells the items of Shop.
+	BehaviorShopkeeper = "shopkeeper"
+)
+
+var Behaviors = []string{BehaviorWander, BehaviorAggressive, BehaviorGuard, BehaviorShopkeeper}
+
+type MobTemplate struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Keywords    []string `json:"keywords,omitempty"`
+	Description string   `json:"description"`
+	Level       int      `json:"level,omitempty"`
+	Damage      int      `json:"damage,omitempty"`
+	Armor       int      `json:"armor,omitempty"`
+	Behaviors   []string `json:"behaviors,omitempty"`
+	// Respawn is the number of seconds before a killed mob comes back. Zero uses the default.
+	Respawn int `json:"respawn,omitempty"`
+	// Greeting is said to players coming into the room.
+	Greeting string `json:"greeting,omitempty"`
+	// Dialog maps words heard in the room to the reply.
+	Dialog map[string]string `json:"dialog,omitempty"`
+	// Shop lists the item templates a shopkeeper sells.
+	Shop   []string `json:"shop,omitempty"`
+	Script string   `json:"script,omitempty"`
+
+	Area string `json:"-"`
+}
+
+// Has reports whether the mob has the behavior.
+func (t *MobTemplate) Has(behavior string) bool {
+	for _, b := range t.Behaviors {
+		if b == behavior {
+			return true
+		}
+	}
+	return false
+}
+
+// Kinds of quest steps. The target of a step is a mob template for kill and talk, an item
+// template for fetch and a room for reach.
+const (
+	StepKill  = "kill"
+	StepFetch = "fetch"
+	StepTalk  = "talk"
+	StepReach = "reach"
+)
+
+type Quest struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Giver is the mob template that hands out the quest. Without one the quest can be taken
+	// anywhere.
+	Giver string `json:"giver,omitempty"`
+	// Level is the lowest level that may take the quest.
+	Level int `json:"level,omitempty"`
+	// Requires lists the quests that have to be completed first.
+	Requires []string `json:"requires,omitempty"`
+	Steps    []*Step  `json:"steps"`
+	Reward   Reward   `json:"reward"`
+
+	Area string `json:"-"`
+}
+
+// Step is done in order. Count defaults to one.
+type Step struct {
+	Kind        string `json:"kind"`
+	Target      string `json:"target"`
+	Count       int    `json:"count,omitempty"`
+	Description string `json:"description"`
+}
+
+func (s *Step) Needed() int {
+	if s.Count <= 0 {
+		return 1
+	}
+	return s.Count
+}
+
+type Reward struct {
+	Exp   int      `json:"exp,omitempty"`
+	Gold  int      `json:"gold,omitempty"`
+	Items []string `json:"items,omitempty"`
+}
+
+// Social is an emote like 웃어. Its messages name the actor and the target with {actor} and
+// {target}, and {actor:이/가} or {target:을/를} add the particle that fits the name.
+type Social struct {
+	Word string `json:"word"`
+	// Alone are the messages when the social is used without a target.
+	Alone SocialMessages `json:"alone"`
+	// Targeted are the messages with a target. A social without them takes none.
+	Targeted *SocialMessages `json:"targeted,omitempty"`
+
+	Area string `json:"-"`
+}
+
+// SocialMessages are what the actor, the target and everyone else in the room see.
+type SocialMessages struct {
+	Actor  string `json:"actor"`
+	Target string `json:"target,omitempty"`
+	Room   string `json:"room"`
+}
+
+// World is the validated content of every area file in a directory.
+type World struct {
+	Start  string
+	Areas  map[string]*Area
+	Rooms  map[string]*Room
+	Items  map[string]*ItemTemplate
+	Mobs   map[string]*MobTemplate
+	Quests map[string]*Quest
+	// Socials are keyed by their command word.
+	Socials map[string]*Social
+	// Scripts maps the script paths used by the areas to their source.
+	Scripts map[string]string
+}
+
+func (w *World) Room(id string) (*Room, bool) {
+	r, ok := w.Rooms[id]
+	return r, ok
+}
+
+func (w *World) StartRoom() *Room {
+	return w.Rooms[w.Start]
+}